@@ -26,6 +26,8 @@ import (
 	"runtime"
 	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	"code.google.com/p/go.tools/go/types"
 )
@@ -38,6 +40,8 @@ var (
 	nextFile   = flag.String("next", "", "optional filename of tentative upcoming API features for the next release. This file can be lazily maintained. It only affects the delta warnings from the -c file printed on success.")
 	verbose    = flag.Bool("v", false, "verbose debugging")
 	forceCtx   = flag.String("contexts", "", "optional comma-separated list of <goos>-<goarch>[-cgo] to override default contexts.")
+	diffFiles  = flag.Bool("diff", false, "compare two API files given as arguments (old.txt new.txt) instead of walking packages")
+	prefix     = flag.String("prefix", "", "only print features beginning with this prefix, such as \"pkg os,\"")
 )
 
 // contexts are the default contexts which are scanned, unless
@@ -117,6 +121,16 @@ func main() {
 		}
 	}
 
+	if *diffFiles {
+		if flag.NArg() != 2 {
+			log.Fatal("usage: api -diff old.txt new.txt")
+		}
+		if !diffAPIFiles(os.Stdout, flag.Arg(0), flag.Arg(1)) {
+			os.Exit(1)
+		}
+		return
+	}
+
 	if *forceCtx != "" {
 		setContexts()
 	}
@@ -174,6 +188,7 @@ func main() {
 			features = append(features, f2)
 		}
 	}
+	features = withPrefix(*prefix, features)
 
 	fail := false
 	defer func() {
@@ -197,11 +212,27 @@ func main() {
 	for _, file := range strings.Split(*checkFile, ",") {
 		required = append(required, fileFeatures(file)...)
 	}
-	optional := fileFeatures(*nextFile)
-	exception := fileFeatures(*exceptFile)
+	required = withPrefix(*prefix, required)
+	optional := withPrefix(*prefix, fileFeatures(*nextFile))
+	exception := withPrefix(*prefix, fileFeatures(*exceptFile))
 	fail = !compareAPI(bw, features, required, optional, exception)
 }
 
+// withPrefix returns the features that begin with prefix,
+// for the -prefix flag. An empty prefix selects all features.
+func withPrefix(prefix string, features []string) []string {
+	if prefix == "" {
+		return features
+	}
+	var out []string
+	for _, f := range features {
+		if strings.HasPrefix(f, prefix) {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
 // export emits the exported package features.
 func (w *Walker) export(pkg *types.Package) {
 	if *verbose {
@@ -299,6 +330,19 @@ func compareAPI(w io.Writer, features, required, optional, exception []string) (
 	return
 }
 
+// diffAPIFiles writes the differences between the API files oldFile
+// and newFile to w, in the same format compareAPI uses for -c.
+// No packages are walked.
+func diffAPIFiles(w io.Writer, oldFile, newFile string) (ok bool) {
+	bw := bufio.NewWriter(w)
+	defer bw.Flush()
+	return compareAPI(bw,
+		withPrefix(*prefix, fileFeatures(newFile)),
+		withPrefix(*prefix, fileFeatures(oldFile)),
+		withPrefix(*prefix, fileFeatures(*nextFile)),
+		withPrefix(*prefix, fileFeatures(*exceptFile)))
+}
+
 func fileFeatures(filename string) []string {
 	if filename == "" {
 		return nil
@@ -342,22 +386,61 @@ func (w *Walker) Features() (fs []string) {
 	return
 }
 
-var parsedFileCache = make(map[string]*ast.File)
+// parsedFileCache holds the files parsed so far, so that walking
+// a package again, as is done for each context, does not parse its
+// files again. It is safe for concurrent use.
+var parsedFileCache = struct {
+	sync.Mutex
+	m map[parseKey]parsedFile
+}{m: make(map[parseKey]parsedFile)}
+
+type parseKey struct {
+	filename string // absolute path
+	mode     parser.Mode
+}
+
+type parsedFile struct {
+	f       *ast.File
+	modTime time.Time // of the file when parsed; zero for generated files
+}
+
+// cachedFile returns the cached parse for key, or nil if there is
+// none or the file has changed since it was parsed.
+func cachedFile(key parseKey) *ast.File {
+	parsedFileCache.Lock()
+	pf, ok := parsedFileCache.m[key]
+	parsedFileCache.Unlock()
+	if !ok {
+		return nil
+	}
+	if !pf.modTime.IsZero() {
+		if fi, err := os.Stat(key.filename); err != nil || !fi.ModTime().Equal(pf.modTime) {
+			return nil
+		}
+	}
+	return pf.f
+}
 
 func (w *Walker) parseFile(dir, file string) (*ast.File, error) {
 	filename := filepath.Join(dir, file)
-	f, _ := parsedFileCache[filename]
-	if f != nil {
+	if abs, err := filepath.Abs(filename); err == nil {
+		filename = abs
+	}
+	const mode = 0
+	key := parseKey{filename, mode}
+	if f := cachedFile(key); f != nil {
 		return f, nil
 	}
 
+	var f *ast.File
 	var err error
+	var modTime time.Time
 
 	// generate missing context-dependent files.
 
 	if w.context != nil && file == fmt.Sprintf("zgoos_%s.go", w.context.GOOS) {
 		src := fmt.Sprintf("package runtime; const theGoos = `%s`", w.context.GOOS)
-		f, err = parser.ParseFile(fset, filename, src, 0)
+		f, err = parser.ParseFile(fset, filename, src, mode)
 		if err != nil {
 			log.Fatalf("incorrect generated file: %s", err)
 		}
@@ -365,20 +448,27 @@ func (w *Walker) parseFile(dir, file string) (*ast.File, error) {
 
 	if w.context != nil && file == fmt.Sprintf("zgoarch_%s.go", w.context.GOARCH) {
 		src := fmt.Sprintf("package runtime; const theGoarch = `%s`", w.context.GOARCH)
-		f, err = parser.ParseFile(fset, filename, src, 0)
+		f, err = parser.ParseFile(fset, filename, src, mode)
 		if err != nil {
 			log.Fatalf("incorrect generated file: %s", err)
 		}
 	}
 
 	if f == nil {
-		f, err = parser.ParseFile(fset, filename, nil, 0)
+		fi, err := os.Stat(filename)
+		if err != nil {
+			return nil, err
+		}
+		modTime = fi.ModTime()
+		f, err = parser.ParseFile(fset, filename, nil, mode)
 		if err != nil {
 			return nil, err
 		}
 	}
 
-	parsedFileCache[filename] = f
+	parsedFileCache.Lock()
+	parsedFileCache.m[key] = parsedFile{f, modTime}
+	parsedFileCache.Unlock()
 	return f, nil
 }
 
@@ -392,8 +482,9 @@ func contains(list []string, s string) bool {
 }
 
 var (
-	pkgCache = map[string]*types.Package{} // map tagKey to package
-	pkgTags  = map[string][]string{}       // map import dir to list of relevant tags
+	pkgCache  = map[string]*types.Package{} // map tagKey to package
+	pkgTags   = map[string][]string{}       // map import dir to list of relevant tags
+	reexports = map[string]string{}         // map "pkg.T" to "otherpkg.U" for type T otherpkg.U
 )
 
 // tagKey returns the tag-based key to use in the pkgCache.
@@ -504,11 +595,7 @@ func (w *Walker) Import(name string) (pkg *types.Package) {
 	conf := types.Config{
 		IgnoreFuncBodies: true,
 		FakeImportC:      true,
-		Import: func(imports map[string]*types.Package, name string) (*types.Package, error) {
-			pkg := w.Import(name)
-			imports[name] = pkg
-			return pkg, nil
-		},
+		Import:           w.importFunc,
 	}
 	pkg, err = conf.Check(name, fset, files, nil)
 	if err != nil {
@@ -520,11 +607,79 @@ func (w *Walker) Import(name string) (pkg *types.Package) {
 	}
 
 	pkgCache[key] = pkg
+	w.recordReexports(name, files)
 
 	w.imported[name] = pkg
 	return
 }
 
+// importFunc is the types.Config Import function used by the Walker.
+func (w *Walker) importFunc(imports map[string]*types.Package, name string) (*types.Package, error) {
+	pkg := w.Import(name)
+	imports[name] = pkg
+	return pkg, nil
+}
+
+// WalkFile emits the exported API declared in the single source file
+// filename, which belongs to the package with import path name.
+// The package's other files are not read, so references to their
+// declarations cannot be resolved, and features mentioning them may
+// be incomplete. Imported packages are walked as usual.
+func (w *Walker) WalkFile(name, filename string) error {
+	f, err := parser.ParseFile(fset, filename, nil, 0)
+	if err != nil {
+		return err
+	}
+	files := []*ast.File{f}
+	conf := types.Config{
+		IgnoreFuncBodies: true,
+		FakeImportC:      true,
+		Import:           w.importFunc,
+		// Keep going past references to the rest of the package.
+		Error: func(error) {},
+	}
+	pkg, err := conf.Check(name, fset, files, nil)
+	if pkg == nil {
+		return fmt.Errorf("error typechecking %s: %v", filename, err)
+	}
+	w.recordReexports(name, files)
+	w.export(pkg)
+	return nil
+}
+
+// recordReexports records in reexports the exported types of package
+// name that are declared directly as a type from another package,
+// as in type T otherpkg.U, with that package's full import path.
+func (w *Walker) recordReexports(name string, files []*ast.File) {
+	for _, f := range files {
+		imports := map[string]string{} // local name to import path
+		for _, spec := range f.Imports {
+			path := strings.Trim(spec.Path.Value, "`\"")
+			if spec.Name != nil {
+				imports[spec.Name.Name] = path
+			} else if pkg := w.imported[path]; pkg != nil && pkg != &importing {
+				imports[pkg.Name()] = path
+			}
+		}
+		for _, decl := range f.Decls {
+			d, ok := decl.(*ast.GenDecl)
+			if !ok || d.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range d.Specs {
+				ts := spec.(*ast.TypeSpec)
+				sel, ok := ts.Type.(*ast.SelectorExpr)
+				if !ok || !ts.Name.IsExported() {
+					continue
+				}
+				if x, ok := sel.X.(*ast.Ident); ok && imports[x.Name] != "" {
+					reexports[name+"."+ts.Name.Name] = imports[x.Name] + "." + sel.Sel.Name
+				}
+			}
+		}
+	}
+}
+
 // pushScope enters a new scope (walking a package, type, node, etc)
 // and returns a function that will leave the scope (with sanity checking
 // for mismatched pushes & pops)
@@ -709,6 +864,10 @@ func (w *Walker) emitObj(obj types.Object) {
 func (w *Walker) emitType(obj *types.TypeName) {
 	name := obj.Name()
 	typ := obj.Type()
+	if re := reexports[obj.Pkg().Path()+"."+name]; re != "" {
+		// Record where a type declared as type T otherpkg.U comes from.
+		w.emitf("type %s %s", name, re)
+	}
 	switch typ := typ.Underlying().(type) {
 	case *types.Struct:
 		w.emitStructType(name, typ)
@@ -15,9 +15,11 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"reflect"
 	"sort"
 	"strings"
 	"testing"
+	"time"
 )
 
 var (
@@ -77,6 +79,110 @@ func TestGolden(t *testing.T) {
 	}
 }
 
+func TestWalkFile(t *testing.T) {
+	// p1 has a single file, so walking just that file
+	// must produce the whole package API.
+	w := NewWalker(nil, "testdata/src/pkg")
+	if err := w.WalkFile("p1", filepath.Join("testdata", "src", "pkg", "p1", "p1.go")); err != nil {
+		t.Fatal(err)
+	}
+	bs, err := ioutil.ReadFile(filepath.Join("testdata", "src", "pkg", "p1", "golden.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := 0
+	for _, feature := range strings.Split(string(bs), "\n") {
+		if feature == "" {
+			continue
+		}
+		n++
+		if !w.features[feature] {
+			t.Errorf("WalkFile: missing feature %q", feature)
+		}
+	}
+	if len(w.features) != n {
+		t.Errorf("WalkFile found %d features, want %d", len(w.features), n)
+	}
+}
+
+func TestWalkerContext(t *testing.T) {
+	// Files constrained to a GOOS are included exactly when
+	// the Walker's context names that GOOS.
+	root, err := ioutil.TempDir("", "goapi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	dir := filepath.Join(root, "q")
+	if err := os.Mkdir(dir, 0777); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"q.go":         "package q\nfunc F() {}\n",
+		"q_linux.go":   "package q\nfunc L() {}\n",
+		"q_windows.go": "package q\nfunc W() {}\n",
+	}
+	for name, src := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(src), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, tt := range []struct {
+		goos string
+		want []string
+	}{
+		{"linux", []string{"pkg q, func F()", "pkg q, func L()"}},
+		{"windows", []string{"pkg q, func F()", "pkg q, func W()"}},
+		{"darwin", []string{"pkg q, func F()"}},
+	} {
+		w := NewWalker(&build.Context{GOOS: tt.goos, GOARCH: "amd64", Compiler: "gc"}, root)
+		w.export(w.Import("q"))
+		if got := w.Features(); strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+			t.Errorf("%s: features = %q, want %q", tt.goos, got, tt.want)
+		}
+	}
+}
+
+func TestParseFileCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "goapi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "c.go")
+	if err := ioutil.WriteFile(filename, []byte("package c\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	w := NewWalker(nil, dir)
+	f1, err := w.parseFile(dir, "c.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f2, err := w.parseFile(dir, "c.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f1 != f2 {
+		t.Errorf("second parse of unchanged file was not cached")
+	}
+
+	// A changed file is parsed again.
+	if err := ioutil.WriteFile(filename, []byte("package c\nconst C = 1\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(filename, later, later); err != nil {
+		t.Fatal(err)
+	}
+	f3, err := w.parseFile(dir, "c.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f3 == f1 || len(f3.Decls) != 1 {
+		t.Errorf("changed file was not parsed again")
+	}
+}
+
 func TestCompareAPI(t *testing.T) {
 	tests := []struct {
 		name                                    string
@@ -142,6 +248,55 @@ func TestCompareAPI(t *testing.T) {
 	}
 }
 
+func TestDiffAPIFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "api-diff")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	oldFile := filepath.Join(dir, "old.txt")
+	newFile := filepath.Join(dir, "new.txt")
+	if err := ioutil.WriteFile(oldFile, []byte("pkg p, func B()\npkg p, func A()\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(newFile, []byte("pkg p, func C()\npkg p, func A()\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	buf := new(bytes.Buffer)
+	if diffAPIFiles(buf, oldFile, newFile) {
+		t.Errorf("diffAPIFiles = true; want false for removed feature")
+	}
+	want := "-pkg p, func B()\n+pkg p, func C()\n"
+	if got := buf.String(); got != want {
+		t.Errorf("output differs\nGOT:\n%s\nWANT:\n%s", got, want)
+	}
+}
+
+func TestWithPrefix(t *testing.T) {
+	features := []string{
+		"pkg os, func Exit(int)",
+		"pkg os (linux-386), const O_DIRECT = 16384",
+		"pkg os/exec, func Command(string, ...string) *Cmd",
+		"pkg syscall, func Exit(int)",
+	}
+	tests := []struct {
+		prefix string
+		want   []string
+	}{
+		{"", features},
+		{"pkg os", features[:3]},
+		{"pkg os,", features[:1]},
+		{"pkg net", nil},
+	}
+	for _, tt := range tests {
+		if got := withPrefix(tt.prefix, features); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("withPrefix(%q) = %q; want %q", tt.prefix, got, tt.want)
+		}
+	}
+}
+
 func BenchmarkAll(b *testing.B) {
 	stds, err := exec.Command("go", "list", "std").Output()
 	if err != nil {
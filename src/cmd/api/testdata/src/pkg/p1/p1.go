@@ -43,6 +43,9 @@ type myInt int
 
 type MyInt int
 
+// TwoerAlias is declared as a type from another package.
+type TwoerAlias ptwo.Twoer
+
 type Time struct{}
 
 type S struct {
@@ -210,3 +213,18 @@ type ByteStruct struct {
 	B byte
 	R rune
 }
+
+// Methods promoted from embedded fields follow Go's rules:
+// a method on the outer type shadows a promoted one, and
+// methods found at the same depth in two fields are not promoted.
+
+type Shadow struct {
+	S
+}
+
+func (Shadow) StructValueMethod(x int) {}
+
+type AmbiguousTB struct {
+	T
+	B
+}
@@ -22,12 +22,14 @@ If the -diff flag is set, no files are rewritten. Instead fix prints
 the differences a rewrite would introduce.
 
 The -r flag restricts the set of rewrites considered to those in the
-named list.  By default fix considers all known rewrites.  Fix's
-rewrites are idempotent, so that it is safe to apply fix to updated
-or partially updated code even without using the -r flag.
+named list, for example -r=url,error.  By default fix considers all
+known rewrites.  Fix's rewrites are idempotent, so that it is safe to
+apply fix to updated or partially updated code even without using the
+-r flag.
 
 Fix prints the full list of fixes it can apply in its help output;
-to see them, run go tool fix -?.
+to see them, run go tool fix -?.  The -list flag prints just the name
+and a one-line summary of each fix.
 
 Fix does not make backup copies of the files that it edits.
 Instead, use a version control system's ``diff'' functionality to inspect
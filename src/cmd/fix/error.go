@@ -0,0 +1,72 @@
+// Copyright 2011 The Go Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import "go/ast"
+
+func init() {
+	register(errorFix)
+}
+
+var errorFix = fix{
+	"error",
+	"2011-11-02",
+	errorFn,
+	`Use the predeclared type error instead of os.Error.
+
+This fix rewrites
+	os.Error -> error
+	err.String() -> err.Error()
+where err is a value of type os.Error, or a variable named err
+whose type cannot be determined. The os import is deleted if
+it is no longer used.
+`,
+}
+
+func errorFn(f *ast.File) bool {
+	if !imports(f, "os") {
+		return false
+	}
+
+	// Find the error values before their type is rewritten.
+	typeof, _ := typecheck(&TypeConfig{}, f)
+
+	fixed := false
+	walk(f, func(n interface{}) {
+		switch n := n.(type) {
+		case *ast.CallExpr:
+			sel, ok := n.Fun.(*ast.SelectorExpr)
+			if !ok || sel.Sel.Name != "String" || len(n.Args) != 0 {
+				return
+			}
+			if isErrorValue(typeof, sel.X) {
+				sel.Sel.Name = "Error"
+				fixed = true
+			}
+		case *ast.Expr:
+			if sel, ok := (*n).(*ast.SelectorExpr); ok && isPkgDot(sel, "os", "Error") {
+				*n = &ast.Ident{NamePos: sel.Pos(), Name: "error"}
+				fixed = true
+			}
+		}
+	})
+
+	if fixed && !usesImport(f, "os") {
+		deleteImport(f, "os")
+	}
+	return fixed
+}
+
+// isErrorValue reports whether x is known to have type os.Error,
+// or is a variable named err of unknown type.
+func isErrorValue(typeof map[interface{}]string, x ast.Expr) bool {
+	switch typeof[x] {
+	case "os.Error", "error":
+		return true
+	case "":
+		return isName(x, "err")
+	}
+	return false
+}
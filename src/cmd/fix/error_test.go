@@ -0,0 +1,125 @@
+// Copyright 2011 The Go Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+func init() {
+	addTestCases(errorTests, errorFn)
+}
+
+var errorTests = []testCase{
+	{
+		Name: "error.0",
+		In: `package main
+
+import "os"
+
+type T struct {
+	err os.Error
+}
+
+func f(e os.Error) string {
+	var x os.Error = e
+	return x.String() + e.String()
+}
+
+func g() (int, os.Error) {
+	return 0, nil
+}
+`,
+		Out: `package main
+
+type T struct {
+	err error
+}
+
+func f(e error) string {
+	var x error = e
+	return x.Error() + e.Error()
+}
+
+func g() (int, error) {
+	return 0, nil
+}
+`,
+	},
+	{
+		Name: "error.1",
+		In: `package main
+
+import (
+	"fmt"
+	"os"
+)
+
+func f(s fmt.Stringer) string {
+	_, err := os.Open("x")
+	if err != nil {
+		return err.String()
+	}
+	return s.String()
+}
+
+func g() (os.Error, []os.Error, map[string]os.Error) {
+	return nil, nil, nil
+}
+`,
+		Out: `package main
+
+import (
+	"fmt"
+	"os"
+)
+
+func f(s fmt.Stringer) string {
+	_, err := os.Open("x")
+	if err != nil {
+		return err.Error()
+	}
+	return s.String()
+}
+
+func g() (error, []error, map[string]error) {
+	return nil, nil, nil
+}
+`,
+	},
+	{
+		Name: "error.2",
+		In: `package main
+
+import "os"
+
+func f() os.Error {
+	_, err := g()
+	return err
+}
+
+func g() (int, os.Error) {
+	return 0, os.NewError("g")
+}
+
+func h(err os.Error) string {
+	return (err).String() + f().String()
+}
+`,
+		Out: `package main
+
+import "os"
+
+func f() error {
+	_, err := g()
+	return err
+}
+
+func g() (int, error) {
+	return 0, os.NewError("g")
+}
+
+func h(err error) string {
+	return (err).Error() + f().Error()
+}
+`,
+	},
+}
@@ -23,6 +23,15 @@ type fix struct {
 	desc string
 }
 
+// summary returns the first line of the fix's description.
+func (f fix) summary() string {
+	desc := strings.TrimSpace(f.desc)
+	if i := strings.Index(desc, "\n"); i >= 0 {
+		desc = desc[:i]
+	}
+	return desc
+}
+
 // main runs sort.Sort(byName(fixes)) before printing list of fixes.
 type byName []fix
 
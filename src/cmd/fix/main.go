@@ -36,11 +36,13 @@ var allowed, force map[string]bool
 
 var doDiff = flag.Bool("diff", false, "display diffs instead of rewriting files")
 
+var doList = flag.Bool("list", false, "list the available rewrites and exit")
+
 // enable for debugging fix failures
 const debug = false // display incorrectly reformatted source and exit
 
 func usage() {
-	fmt.Fprintf(os.Stderr, "usage: go tool fix [-diff] [-r fixname,...] [-force fixname,...] [path ...]\n")
+	fmt.Fprintf(os.Stderr, "usage: go tool fix [-diff] [-list] [-r fixname,...] [-force fixname,...] [path ...]\n")
 	flag.PrintDefaults()
 	fmt.Fprintf(os.Stderr, "\nAvailable rewrites are:\n")
 	sort.Sort(byName(fixes))
@@ -57,20 +59,22 @@ func main() {
 	flag.Usage = usage
 	flag.Parse()
 
+	if *doList {
+		sort.Sort(byName(fixes))
+		for _, f := range fixes {
+			fmt.Printf("%-16s %s\n", f.name, f.summary())
+		}
+		os.Exit(0)
+	}
+
 	sort.Sort(byDate(fixes))
 
 	if *allowedRewrites != "" {
-		allowed = make(map[string]bool)
-		for _, f := range strings.Split(*allowedRewrites, ",") {
-			allowed[f] = true
-		}
+		allowed = fixSet("r", *allowedRewrites)
 	}
 
 	if *forceRewrites != "" {
-		force = make(map[string]bool)
-		for _, f := range strings.Split(*forceRewrites, ",") {
-			force[f] = true
-		}
+		force = fixSet("force", *forceRewrites)
 	}
 
 	if flag.NArg() == 0 {
@@ -97,6 +101,24 @@ func main() {
 	os.Exit(exitCode)
 }
 
+// fixSet returns the set of fix names in the comma-separated list
+// given as the value of the named flag. It exits if a name is unknown.
+func fixSet(flagName, list string) map[string]bool {
+	known := make(map[string]bool)
+	for _, f := range fixes {
+		known[f.name] = true
+	}
+	set := make(map[string]bool)
+	for _, name := range strings.Split(list, ",") {
+		if !known[name] {
+			fmt.Fprintf(os.Stderr, "fix: unknown rewrite %q in -%s; see go tool fix -list\n", name, flagName)
+			os.Exit(2)
+		}
+		set[name] = true
+	}
+	return set
+}
+
 const parserMode = parser.ParseComments
 
 func gofmtFile(f *ast.File) ([]byte, error) {
@@ -183,7 +205,7 @@ func processFile(filename string, useStdin bool) error {
 			return fmt.Errorf("computing diff: %s", err)
 		}
 		fmt.Printf("diff %s fixed/%s\n", filename, filename)
-		os.Stdout.Write(data)
+		os.Stdout.Write(relabelDiff(data, filename, "fixed/"+filename))
 		return nil
 	}
 
@@ -256,3 +278,18 @@ func diff(b1, b2 []byte) (data []byte, err error) {
 	}
 	return
 }
+
+// relabelDiff replaces the temporary file names in the
+// header lines of the unified diff data with old and new.
+func relabelDiff(data []byte, old, new string) []byte {
+	lines := bytes.SplitN(data, []byte("\n"), 3)
+	if len(lines) < 3 ||
+		!bytes.HasPrefix(lines[0], []byte("--- ")) ||
+		!bytes.HasPrefix(lines[1], []byte("+++ ")) {
+		return data
+	}
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "--- %s\n+++ %s\n", old, new)
+	buf.Write(lines[2])
+	return buf.Bytes()
+}
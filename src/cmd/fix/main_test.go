@@ -119,6 +119,25 @@ func TestRewrite(t *testing.T) {
 	}
 }
 
+// TestFixedOutput checks that the expected output of every test case
+// is a fixed point of all fixes together, not just of its own fix,
+// so that running fix again over updated code, or with overlapping
+// fixes, leaves it alone.
+func TestFixedOutput(t *testing.T) {
+	for _, tt := range testCases {
+		out, fixed, ok := parseFixPrint(t, nil, tt.Name+" output", tt.Out, true)
+		if !ok {
+			continue
+		}
+		if fixed {
+			t.Errorf("%s: fixes applied to expected output", tt.Name)
+			if out != tt.Out {
+				tdiff(t, tt.Out, out)
+			}
+		}
+	}
+}
+
 func tdiff(t *testing.T, a, b string) {
 	data, err := diff([]byte(a), []byte(b))
 	if err != nil {
@@ -127,3 +146,31 @@ func tdiff(t *testing.T, a, b string) {
 	}
 	t.Error(string(data))
 }
+
+func TestDiff(t *testing.T) {
+	data, err := diff([]byte("a\nb\n"), []byte("a\nc\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(relabelDiff(data, "x.go", "fixed/x.go"))
+	want := "--- x.go\n+++ fixed/x.go\n"
+	if !strings.HasPrefix(got, want) {
+		t.Fatalf("diff header:\n%s\nwant prefix:\n%s", got, want)
+	}
+	if !strings.Contains(got, "\n-b\n+c\n") {
+		t.Fatalf("diff does not show the change:\n%s", got)
+	}
+}
+
+func TestFixNames(t *testing.T) {
+	seen := make(map[string]bool)
+	for _, f := range fixes {
+		if seen[f.name] {
+			t.Errorf("fix %s registered twice", f.name)
+		}
+		seen[f.name] = true
+		if f.summary() == "" {
+			t.Errorf("fix %s has no description", f.name)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+// Copyright 2011 The Go Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+func init() {
+	register(sortsliceFix)
+}
+
+var sortsliceFix = fix{
+	"sortslice",
+	"2011-06-26",
+	sortslice,
+	`Adapt code to the sort package convenience renames.
+
+	sort.SortInts -> sort.Ints
+	sort.SortFloat64s -> sort.Float64s
+	sort.SortStrings -> sort.Strings
+	sort.IntArray -> sort.IntSlice
+	sort.Float64Array -> sort.Float64Slice
+	sort.StringArray -> sort.StringSlice
+`,
+}
+
+var sortsliceRenames = []rename{
+	{"sort", "", "sort.SortInts", "sort.Ints"},
+	{"sort", "", "sort.SortFloat64s", "sort.Float64s"},
+	{"sort", "", "sort.SortStrings", "sort.Strings"},
+	{"sort", "", "sort.IntArray", "sort.IntSlice"},
+	{"sort", "", "sort.Float64Array", "sort.Float64Slice"},
+	{"sort", "", "sort.StringArray", "sort.StringSlice"},
+}
+
+var sortslice = renameFix(sortsliceRenames)
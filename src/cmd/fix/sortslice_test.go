@@ -0,0 +1,88 @@
+// Copyright 2011 The Go Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+func init() {
+	addTestCases(sortsliceTests, sortslice)
+}
+
+var sortsliceTests = []testCase{
+	{
+		Name: "sortslice.0",
+		In: `package main
+
+import "sort"
+
+func f(a []int, b []float64, c []string) {
+	sort.SortInts(a)
+	sort.SortFloat64s(b)
+	sort.SortStrings(c)
+	sort.Sort(sort.IntArray(a))
+	sort.Sort(sort.Float64Array(b))
+	sort.Sort(sort.StringArray(c))
+	var x sort.IntArray = a
+	var y *sort.StringArray
+}
+`,
+		Out: `package main
+
+import "sort"
+
+func f(a []int, b []float64, c []string) {
+	sort.Ints(a)
+	sort.Float64s(b)
+	sort.Strings(c)
+	sort.Sort(sort.IntSlice(a))
+	sort.Sort(sort.Float64Slice(b))
+	sort.Sort(sort.StringSlice(c))
+	var x sort.IntSlice = a
+	var y *sort.StringSlice
+}
+`,
+	},
+	{
+		Name: "sortslice.1",
+		In: `package main
+
+import "sort"
+
+type byLen []string
+
+func (b byLen) Len() int           { return len(b) }
+func (b byLen) Less(i, j int) bool { return len(b[i]) < len(b[j]) }
+func (b byLen) Swap(i, j int)      { b[i], b[j] = b[j], b[i] }
+
+var _ sort.Interface = byLen(nil)
+
+func f(s []string) {
+	sort.Sort(byLen(s))
+	if !sort.IsSorted(sort.StringArray(s)) {
+		sort.SortStrings(s)
+	}
+	_ = sort.SearchStrings(s, "x")
+}
+`,
+		Out: `package main
+
+import "sort"
+
+type byLen []string
+
+func (b byLen) Len() int           { return len(b) }
+func (b byLen) Less(i, j int) bool { return len(b[i]) < len(b[j]) }
+func (b byLen) Swap(i, j int)      { b[i], b[j] = b[j], b[i] }
+
+var _ sort.Interface = byLen(nil)
+
+func f(s []string) {
+	sort.Sort(byLen(s))
+	if !sort.IsSorted(sort.StringSlice(s)) {
+		sort.Strings(s)
+	}
+	_ = sort.SearchStrings(s, "x")
+}
+`,
+	},
+}
@@ -0,0 +1,107 @@
+// Copyright 2011 The Go Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"go/ast"
+	"go/token"
+)
+
+func init() {
+	register(strconvFix)
+}
+
+var strconvFix = fix{
+	"strconv",
+	"2011-12-01",
+	strconvFn,
+	`Convert to new strconv API.
+
+Calls such as strconv.Atoi64(s) and strconv.Itob(i, b) become
+strconv.ParseInt(s, 10, 64) and strconv.FormatInt(int64(i), b).
+Calls to strconv.Atof32 and strconv.Atoui are left alone, with a
+warning, because the new functions return a different type.
+`,
+}
+
+// A strconvRename describes how to rewrite a call of strconv.old:
+// the call becomes strconv.new, its first argument is converted
+// to conv (if set), and the arguments in add are appended.
+type strconvRename struct {
+	old   string
+	nargs int
+	new   string
+	conv  string
+	add   []string
+}
+
+var strconvRenames = []strconvRename{
+	{"Atob", 1, "ParseBool", "", nil},
+	{"Atof64", 1, "ParseFloat", "", []string{"64"}},
+	{"AtofN", 2, "ParseFloat", "", nil},
+	{"Atoi64", 1, "ParseInt", "", []string{"10", "64"}},
+	{"Atoui64", 1, "ParseUint", "", []string{"10", "64"}},
+	{"Btoi64", 2, "ParseInt", "", []string{"64"}},
+	{"Btoui64", 2, "ParseUint", "", []string{"64"}},
+	{"Btoa", 1, "FormatBool", "", nil},
+	{"Ftoa32", 3, "FormatFloat", "float64", []string{"32"}},
+	{"Ftoa64", 3, "FormatFloat", "", []string{"64"}},
+	{"FtoaN", 4, "FormatFloat", "", nil},
+	{"Itoa64", 1, "FormatInt", "", []string{"10"}},
+	{"Itob", 2, "FormatInt", "int64", nil},
+	{"Itob64", 2, "FormatInt", "", nil},
+	{"Uitoa", 1, "FormatUint", "uint64", []string{"10"}},
+	{"Uitoa64", 1, "FormatUint", "", []string{"10"}},
+	{"Uitob", 2, "FormatUint", "uint64", nil},
+	{"Uitob64", 2, "FormatUint", "", nil},
+}
+
+func strconvFn(f *ast.File) bool {
+	if !imports(f, "strconv") {
+		return false
+	}
+
+	fixed := false
+	walk(f, func(n interface{}) {
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return
+		}
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok || !isTopName(sel.X, "strconv") {
+			return
+		}
+		switch sel.Sel.Name {
+		case "Atof32":
+			warn(call.Pos(), "strconv.Atof32 not rewritten: use float32 result of strconv.ParseFloat(s, 32)")
+			return
+		case "Atoui":
+			warn(call.Pos(), "strconv.Atoui not rewritten: use uint result of strconv.ParseUint(s, 10, 0)")
+			return
+		}
+		for _, r := range strconvRenames {
+			if sel.Sel.Name != r.old {
+				continue
+			}
+			if len(call.Args) != r.nargs || call.Ellipsis.IsValid() {
+				warn(call.Pos(), "strconv.%s call not rewritten: unexpected arguments", r.old)
+				return
+			}
+			sel.Sel.Name = r.new
+			if r.conv != "" {
+				call.Args[0] = &ast.CallExpr{
+					Fun:  ast.NewIdent(r.conv),
+					Args: []ast.Expr{call.Args[0]},
+				}
+			}
+			for _, lit := range r.add {
+				call.Args = append(call.Args, &ast.BasicLit{Kind: token.INT, Value: lit})
+			}
+			fixed = true
+			return
+		}
+	})
+	return fixed
+}
@@ -0,0 +1,109 @@
+// Copyright 2011 The Go Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+func init() {
+	addTestCases(strconvTests, strconvFn)
+}
+
+var strconvTests = []testCase{
+	{
+		Name: "strconv.0",
+		In: `package main
+
+import "strconv"
+
+func f() {
+	strconv.Atob("true")
+	strconv.Atof64("1.5")
+	strconv.AtofN("1.5", 32)
+	strconv.Atoi("1")
+	strconv.Atoi64("1")
+	strconv.Atoui64("1")
+	strconv.Btoi64("ff", 16)
+	strconv.Btoui64("ff", 16)
+	strconv.Btoa(true)
+}
+`,
+		Out: `package main
+
+import "strconv"
+
+func f() {
+	strconv.ParseBool("true")
+	strconv.ParseFloat("1.5", 64)
+	strconv.ParseFloat("1.5", 32)
+	strconv.Atoi("1")
+	strconv.ParseInt("1", 10, 64)
+	strconv.ParseUint("1", 10, 64)
+	strconv.ParseInt("ff", 16, 64)
+	strconv.ParseUint("ff", 16, 64)
+	strconv.FormatBool(true)
+}
+`,
+	},
+	{
+		Name: "strconv.1",
+		In: `package main
+
+import "strconv"
+
+func f(x float32, y float64, i int, j int64, u uint, v uint64) {
+	strconv.Ftoa32(x, 'g', -1)
+	strconv.Ftoa64(y, 'e', 3)
+	strconv.FtoaN(y, 'f', 2, 64)
+	strconv.Itoa(i)
+	strconv.Itoa64(j)
+	strconv.Itob(i, 2)
+	strconv.Itob64(j, 16)
+	strconv.Uitoa(u)
+	strconv.Uitoa64(v)
+	strconv.Uitob(u+1, 8)
+	strconv.Uitob64(v, 36)
+}
+`,
+		Out: `package main
+
+import "strconv"
+
+func f(x float32, y float64, i int, j int64, u uint, v uint64) {
+	strconv.FormatFloat(float64(x), 'g', -1, 32)
+	strconv.FormatFloat(y, 'e', 3, 64)
+	strconv.FormatFloat(y, 'f', 2, 64)
+	strconv.Itoa(i)
+	strconv.FormatInt(j, 10)
+	strconv.FormatInt(int64(i), 2)
+	strconv.FormatInt(j, 16)
+	strconv.FormatUint(uint64(u), 10)
+	strconv.FormatUint(v, 10)
+	strconv.FormatUint(uint64(u+1), 8)
+	strconv.FormatUint(v, 36)
+}
+`,
+	},
+	{
+		Name: "strconv.2",
+		In: `package main
+
+import "strconv"
+
+// The result types of these changed; they are left alone.
+func f() {
+	strconv.Atof32("1.5")
+	strconv.Atoui("1")
+}
+`,
+		Out: `package main
+
+import "strconv"
+
+// The result types of these changed; they are left alone.
+func f() {
+	strconv.Atof32("1.5")
+	strconv.Atoui("1")
+}
+`,
+	},
+}
@@ -0,0 +1,51 @@
+// Copyright 2011 The Go Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"go/ast"
+	"path"
+)
+
+func init() {
+	register(templateFix)
+}
+
+var templateFix = fix{
+	"template",
+	"2011-11-08",
+	templateFn,
+	`Move the template packages to text/template and html/template.
+
+	template -> text/template
+	template/parse -> text/template/parse
+	exp/template/html -> html/template
+
+Exp/template/html was package html; it is imported under that
+name so that references to it keep working.
+`,
+}
+
+var templateRenames = []struct{ old, new string }{
+	{"template", "text/template"},
+	{"template/parse", "text/template/parse"},
+	{"exp/template/html", "html/template"},
+}
+
+func templateFn(f *ast.File) bool {
+	fixed := false
+	for _, r := range templateRenames {
+		spec := importSpec(f, r.old)
+		if spec == nil {
+			continue
+		}
+		rewriteImport(f, r.old, r.new)
+		if spec.Name == nil && path.Base(r.old) != path.Base(r.new) {
+			spec.Name = &ast.Ident{NamePos: spec.Path.Pos(), Name: path.Base(r.old)}
+		}
+		fixed = true
+	}
+	return fixed
+}
@@ -0,0 +1,77 @@
+// Copyright 2011 The Go Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+func init() {
+	addTestCases(templateTests, templateFn)
+}
+
+var templateTests = []testCase{
+	{
+		Name: "template.0",
+		In: `package main
+
+import "template"
+
+var t = template.Must(template.New("x").Parse("{{.}}"))
+`,
+		Out: `package main
+
+import "text/template"
+
+var t = template.Must(template.New("x").Parse("{{.}}"))
+`,
+	},
+	{
+		Name: "template.1",
+		In: `package main
+
+import (
+	"template"
+	"template/parse"
+)
+
+func f(t *template.Template) *parse.Tree {
+	return t.Tree
+}
+`,
+		Out: `package main
+
+import (
+	"text/template"
+	"text/template/parse"
+)
+
+func f(t *template.Template) *parse.Tree {
+	return t.Tree
+}
+`,
+	},
+	{
+		Name: "template.2",
+		In: `package main
+
+import (
+	"exp/template/html"
+	"template"
+)
+
+func f(t *template.Template) (*template.Template, error) {
+	return html.Escape(t)
+}
+`,
+		Out: `package main
+
+import (
+	html "html/template"
+	"text/template"
+)
+
+func f(t *template.Template) (*template.Template, error) {
+	return html.Escape(t)
+}
+`,
+	},
+}
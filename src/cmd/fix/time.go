@@ -0,0 +1,197 @@
+// Copyright 2011 The Go Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"go/ast"
+	"go/token"
+	"strconv"
+)
+
+func init() {
+	register(timeFix)
+}
+
+var timeFix = fix{
+	"time",
+	"2011-11-30",
+	timeFn,
+	`Adapt code to the time.Duration API.
+
+This fix rewrites
+	time.Nanoseconds() -> time.Now().UnixNano()
+	time.Seconds() -> time.Now().Unix()
+and adapts the nanosecond arguments of time.Sleep, time.After,
+time.AfterFunc, time.Tick, time.NewTimer and time.NewTicker:
+a constant such as 5e9 becomes 5 * time.Second, and a variable of
+integer type x becomes time.Duration(x). Arguments whose type cannot
+be determined are left alone with a warning.
+`,
+}
+
+// timeDurationFuncs lists the functions whose first argument
+// changed from nanoseconds in an int64 to a time.Duration.
+var timeDurationFuncs = []string{
+	"After",
+	"AfterFunc",
+	"NewTicker",
+	"NewTimer",
+	"Sleep",
+	"Tick",
+}
+
+var timeTypeConfig = &TypeConfig{
+	Func: map[string]string{
+		"time.Nanoseconds": "int64",
+		"time.Seconds":     "int64",
+	},
+}
+
+// timeUnits lists the units used to write constant durations,
+// largest first.
+var timeUnits = []struct {
+	name string
+	ns   int64
+}{
+	{"Hour", 3600e9},
+	{"Minute", 60e9},
+	{"Second", 1e9},
+	{"Millisecond", 1e6},
+	{"Microsecond", 1e3},
+}
+
+func timeFn(f *ast.File) bool {
+	if !imports(f, "time") {
+		return false
+	}
+
+	typeof, _ := typecheck(timeTypeConfig, f)
+
+	fixed := false
+	walk(f, func(n interface{}) {
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return
+		}
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok || !isTopName(sel.X, "time") {
+			return
+		}
+		switch sel.Sel.Name {
+		case "Nanoseconds", "Seconds":
+			if len(call.Args) != 0 {
+				return
+			}
+			if sel.Sel.Name == "Nanoseconds" {
+				sel.Sel.Name = "UnixNano"
+			} else {
+				sel.Sel.Name = "Unix"
+			}
+			sel.X = &ast.CallExpr{Fun: newPkgDot(sel.Pos(), "time", "Now")}
+			fixed = true
+			return
+		}
+		for _, name := range timeDurationFuncs {
+			if sel.Sel.Name == name && len(call.Args) > 0 {
+				if timeDuration(typeof, &call.Args[0]) {
+					fixed = true
+				}
+				return
+			}
+		}
+	})
+	return fixed
+}
+
+// timeDuration rewrites the nanosecond count *x as a time.Duration
+// and reports whether it changed *x.
+func timeDuration(typeof map[interface{}]string, x *ast.Expr) bool {
+	if usesTimeUnit(*x) {
+		// Already written using time.Second and friends.
+		return false
+	}
+	if lit, ok := (*x).(*ast.BasicLit); ok {
+		return timeConstant(x, lit)
+	}
+	if isConstant(*x) {
+		// An untyped constant converts to time.Duration as is.
+		return false
+	}
+	switch typeof[*x] {
+	case "time.Duration":
+		return false
+	case "int", "int8", "int16", "int32", "int64",
+		"uint", "uint8", "uint16", "uint32", "uint64", "uintptr":
+		*x = &ast.CallExpr{
+			Fun:  newPkgDot((*x).Pos(), "time", "Duration"),
+			Args: []ast.Expr{*x},
+		}
+		return true
+	}
+	warn((*x).Pos(), "cannot tell whether %s is a nanosecond count; not converted to time.Duration", gofmt(*x))
+	return false
+}
+
+// timeConstant rewrites the constant nanosecond count lit
+// in the largest time unit that divides it evenly.
+func timeConstant(x *ast.Expr, lit *ast.BasicLit) bool {
+	if lit.Kind != token.INT && lit.Kind != token.FLOAT {
+		return false
+	}
+	v, err := strconv.ParseFloat(lit.Value, 64)
+	if err != nil || v <= 0 || v != float64(int64(v)) {
+		return false
+	}
+	ns := int64(v)
+	for _, u := range timeUnits {
+		if ns%u.ns != 0 {
+			continue
+		}
+		unit := newPkgDot(lit.Pos(), "time", u.name)
+		if ns == u.ns {
+			*x = unit
+		} else {
+			*x = &ast.BinaryExpr{
+				X:  &ast.BasicLit{ValuePos: lit.Pos(), Kind: token.INT, Value: strconv.FormatInt(ns/u.ns, 10)},
+				Op: token.MUL,
+				Y:  unit,
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// usesTimeUnit reports whether x refers to one of the
+// time.Duration constants or to time.Duration itself.
+func usesTimeUnit(x ast.Expr) bool {
+	found := false
+	walk(x, func(n interface{}) {
+		sel, ok := n.(*ast.SelectorExpr)
+		if !ok || !isTopName(sel.X, "time") {
+			return
+		}
+		switch sel.Sel.Name {
+		case "Duration", "Nanosecond", "Microsecond", "Millisecond", "Second", "Minute", "Hour":
+			found = true
+		}
+	})
+	return found
+}
+
+// isConstant reports whether x is built from literals alone.
+func isConstant(x ast.Expr) bool {
+	switch x := x.(type) {
+	case *ast.BasicLit:
+		return true
+	case *ast.ParenExpr:
+		return isConstant(x.X)
+	case *ast.UnaryExpr:
+		return isConstant(x.X)
+	case *ast.BinaryExpr:
+		return isConstant(x.X) && isConstant(x.Y)
+	}
+	return false
+}
@@ -0,0 +1,103 @@
+// Copyright 2011 The Go Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+func init() {
+	addTestCases(timeTests, timeFn)
+}
+
+var timeTests = []testCase{
+	{
+		Name: "time.0",
+		In: `package main
+
+import "time"
+
+func f() int64 {
+	start := time.Nanoseconds()
+	now := time.Seconds()
+	return start + now
+}
+`,
+		Out: `package main
+
+import "time"
+
+func f() int64 {
+	start := time.Now().UnixNano()
+	now := time.Now().Unix()
+	return start + now
+}
+`,
+	},
+	{
+		Name: "time.1",
+		In: `package main
+
+import "time"
+
+func f() {
+	time.Sleep(1e9)
+	time.Sleep(5e8)
+	time.Sleep(250e6)
+	time.Sleep(3600e9)
+	time.Sleep(7)
+	time.Sleep(2 * 1e9)
+	<-time.After(3e9)
+	time.AfterFunc(1e3, nil)
+	time.NewTicker(120e9)
+	time.Sleep(2 * time.Second)
+}
+`,
+		Out: `package main
+
+import "time"
+
+func f() {
+	time.Sleep(time.Second)
+	time.Sleep(500 * time.Millisecond)
+	time.Sleep(250 * time.Millisecond)
+	time.Sleep(time.Hour)
+	time.Sleep(7)
+	time.Sleep(2 * 1e9)
+	<-time.After(3 * time.Second)
+	time.AfterFunc(time.Microsecond, nil)
+	time.NewTicker(2 * time.Minute)
+	time.Sleep(2 * time.Second)
+}
+`,
+	},
+	{
+		Name: "time.2",
+		In: `package main
+
+import "time"
+
+func wait(ns int64, d time.Duration, c *Config) {
+	time.Sleep(ns)
+	time.Sleep(d)
+	t := time.NewTimer(ns)
+	start := time.Nanoseconds()
+	<-time.Tick(start)
+	time.Sleep(c.Timeout)
+	_ = t
+}
+`,
+		Out: `package main
+
+import "time"
+
+func wait(ns int64, d time.Duration, c *Config) {
+	time.Sleep(time.Duration(ns))
+	time.Sleep(d)
+	t := time.NewTimer(time.Duration(ns))
+	start := time.Now().UnixNano()
+	<-time.Tick(time.Duration(start))
+	time.Sleep(c.Timeout)
+	_ = t
+}
+`,
+	},
+}
@@ -0,0 +1,128 @@
+// Copyright 2011 The Go Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import "go/ast"
+
+func init() {
+	register(urlFix)
+}
+
+var urlFix = fix{
+	"url",
+	"2011-08-17",
+	url,
+	`Move the URL pieces of package http into package net/url.
+
+Besides renaming the moved names, this fix rewrites
+	http.EncodeQuery(m) -> url.Values(m).Encode()
+Calls of the pre-ResponseWriter form http.Redirect(c, url, code)
+need a *Request that fix cannot supply, so they are left alone
+with a warning.
+`,
+}
+
+var urlRenames = []struct{ in, out string }{
+	{"URL", "URL"},
+	{"ParseURL", "Parse"},
+	{"ParseURLReference", "ParseWithReference"},
+	{"ParseQuery", "ParseQuery"},
+	{"Values", "Values"},
+	{"URLEscape", "QueryEscape"},
+	{"URLUnescape", "QueryUnescape"},
+	{"URLError", "Error"},
+	{"URLEscapeError", "EscapeError"},
+}
+
+func url(f *ast.File) bool {
+	if !imports(f, "net/http") {
+		return false
+	}
+
+	isURLName := func(n interface{}) (*ast.SelectorExpr, string) {
+		sel, ok := n.(*ast.SelectorExpr)
+		if !ok || !isTopName(sel.X, "http") {
+			return nil, ""
+		}
+		for _, r := range urlRenames {
+			if sel.Sel.Name == r.in {
+				return sel, r.out
+			}
+		}
+		return nil, ""
+	}
+
+	fixed := false
+	walk(f, func(n interface{}) {
+		if sel, _ := isURLName(n); sel != nil {
+			fixed = true
+		}
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return
+		}
+		switch {
+		case isPkgDot(call.Fun, "http", "EncodeQuery") && len(call.Args) == 1:
+			fixed = true
+		case isPkgDot(call.Fun, "http", "Redirect") && len(call.Args) == 3:
+			warn(call.Pos(), "http.Redirect call not rewritten: it now takes a ResponseWriter and a *Request")
+		}
+	})
+	if !fixed {
+		return false
+	}
+
+	// Adding the import renames conflicting top-level names.
+	addImport(f, "net/url")
+
+	// The walk visits every expression, including the elements
+	// of composite literals and the types of map and struct
+	// literals, so nested uses such as map[string]http.Values
+	// are renamed too.
+	// Struct fields named url do not shadow the package,
+	// and their selectors are not renamed, so keep them.
+	fields := make(map[*ast.Object]bool)
+	walk(f, func(n interface{}) {
+		if st, ok := n.(*ast.StructType); ok {
+			for _, field := range st.Fields.List {
+				for _, id := range field.Names {
+					fields[id.Obj] = true
+				}
+			}
+		}
+	})
+
+	walk(f, func(n interface{}) {
+		// Rename local variables, constants and parameters
+		// named url so that they do not shadow the package.
+		if id, ok := n.(*ast.Ident); ok && id.Name == "url" && id.Obj != nil &&
+			(id.Obj.Kind == ast.Var || id.Obj.Kind == ast.Con) && !fields[id.Obj] {
+			id.Name = "url_"
+			return
+		}
+		if sel, out := isURLName(n); sel != nil {
+			sel.X.(*ast.Ident).Name = "url"
+			sel.Sel.Name = out
+			return
+		}
+		// EncodeQuery(m) became the Encode method of Values.
+		if call, ok := n.(*ast.CallExpr); ok && isPkgDot(call.Fun, "http", "EncodeQuery") && len(call.Args) == 1 {
+			pos := call.Fun.Pos()
+			call.Fun = &ast.SelectorExpr{
+				X: &ast.CallExpr{
+					Fun:  newPkgDot(pos, "url", "Values"),
+					Args: call.Args,
+				},
+				Sel: ast.NewIdent("Encode"),
+			}
+			call.Args = nil
+		}
+	})
+
+	if !usesImport(f, "net/http") {
+		deleteImport(f, "net/http")
+	}
+	return true
+}
@@ -0,0 +1,198 @@
+// Copyright 2011 The Go Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+func init() {
+	addTestCases(urlTests, url)
+}
+
+var urlTests = []testCase{
+	{
+		Name: "url.0",
+		In: `package main
+
+import (
+	"net/http"
+)
+
+func f() {
+	var _ http.URL
+	http.ParseURL("a")
+	http.ParseURLReference("a")
+	http.ParseQuery("")
+	m := http.Values{"a": []string{"b"}}
+	http.URLEscape("a")
+	http.URLUnescape("a")
+	var x http.URLError
+	var y http.URLEscapeError
+}
+`,
+		Out: `package main
+
+import "net/url"
+
+func f() {
+	var _ url.URL
+	url.Parse("a")
+	url.ParseWithReference("a")
+	url.ParseQuery("")
+	m := url.Values{"a": []string{"b"}}
+	url.QueryEscape("a")
+	url.QueryUnescape("a")
+	var x url.Error
+	var y url.EscapeError
+}
+`,
+	},
+	{
+		Name: "url.1",
+		In: `package main
+
+import (
+	"net/http"
+)
+
+func f() {
+	http.ParseURL("a")
+	var url = 23
+	url, x := 45, y
+	_ = http.Get
+}
+
+func g(url string) string {
+	return url
+}
+`,
+		Out: `package main
+
+import (
+	"net/http"
+	"net/url"
+)
+
+func f() {
+	url.Parse("a")
+	var url_ = 23
+	url_, x := 45, y
+	_ = http.Get
+}
+
+func g(url_ string) string {
+	return url_
+}
+`,
+	},
+	{
+		Name: "url.2",
+		In: `package main
+
+import "net/http"
+
+type T struct {
+	V http.Values
+}
+
+var m = map[string]http.Values{
+	"a": http.Values{"b": {"c"}},
+	"d": {"e": {"f"}},
+}
+
+var t = T{V: http.Values{"g": nil}}
+
+var s = []struct{ V http.Values }{{V: http.Values{}}}
+`,
+		Out: `package main
+
+import "net/url"
+
+type T struct {
+	V url.Values
+}
+
+var m = map[string]url.Values{
+	"a": url.Values{"b": {"c"}},
+	"d": {"e": {"f"}},
+}
+
+var t = T{V: url.Values{"g": nil}}
+
+var s = []struct{ V url.Values }{{V: url.Values{}}}
+`,
+	},
+	{
+		Name: "url.3",
+		In: `package main
+
+import "net/http"
+
+func f(m map[string][]string) string {
+	http.Redirect(c, "/", 302)
+	return "?" + http.EncodeQuery(m)
+}
+`,
+		Out: `package main
+
+import (
+	"net/http"
+	"net/url"
+)
+
+func f(m map[string][]string) string {
+	http.Redirect(c, "/", 302)
+	return "?" + url.Values(m).Encode()
+}
+`,
+	},
+	{
+		Name: "url.4",
+		In: `package main
+
+import "net/http"
+
+func f(w http.ResponseWriter, r *http.Request) {
+	http.Redirect(w, r, "/", http.StatusFound)
+	http.Error(w, "oops", http.StatusInternalServerError)
+}
+`,
+		Out: `package main
+
+import "net/http"
+
+func f(w http.ResponseWriter, r *http.Request) {
+	http.Redirect(w, r, "/", http.StatusFound)
+	http.Error(w, "oops", http.StatusInternalServerError)
+}
+`,
+	},
+	{
+		Name: "url.5",
+		In: `package main
+
+import "net/http"
+
+type T struct {
+	url string
+}
+
+func f(t *T) (*http.URL, error) {
+	url := t.url
+	return http.ParseURL(url)
+}
+`,
+		Out: `package main
+
+import "net/url"
+
+type T struct {
+	url string
+}
+
+func f(t *T) (*url.URL, error) {
+	url_ := t.url
+	return url.Parse(url_)
+}
+`,
+	},
+}
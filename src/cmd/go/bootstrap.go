@@ -6,7 +6,8 @@
 
 // This code is compiled only into the bootstrap 'go' binary.
 // These stubs avoid importing packages with large dependency
-// trees, like the use of "net/http" in vcs.go.
+// trees, like the use of "net/http" in vcs.go, or that cmd/dist
+// cannot build, like "crypto/sha1" in cgocache.go.
 
 package main
 
@@ -28,3 +29,13 @@ func httpsOrHTTP(importPath string) (string, io.ReadCloser, error) {
 func parseMetaGoImports(r io.Reader) ([]metaImport, error) {
 	panic("unreachable")
 }
+
+func (b *builder) cgoCacheKey(p *Package, cgoExe, obj string, gccfiles, gxxfiles, mfiles, cflags, cxxflags []string, flags ...[]string) string {
+	return ""
+}
+
+func (b *builder) cgoCacheGet(key, obj string) (outGo, outObj []string, ok bool) {
+	return nil, nil, false
+}
+
+func (b *builder) cgoCachePut(key, obj string, outGo, outObj []string) {}
@@ -7,6 +7,7 @@ package main
 import (
 	"bytes"
 	"container/heap"
+	"encoding/json"
 	"errors"
 	"flag"
 	"fmt"
@@ -20,6 +21,7 @@ import (
 	"path/filepath"
 	"regexp"
 	"runtime"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -27,7 +29,7 @@ import (
 )
 
 var cmdBuild = &Command{
-	UsageLine: "build [-o output] [build flags] [packages]",
+	UsageLine: "build [-o output] [-run] [-objdir dir] [build flags] [packages]",
 	Short:     "compile packages and dependencies",
 	Long: `
 Build compiles the packages named by the import paths,
@@ -36,6 +38,10 @@ along with their dependencies, but it does not install the results.
 If the arguments are a list of .go files, build treats them as a list
 of source files specifying a single package.
 
+An argument of - causes build to read further import paths from
+standard input, one per line. This avoids limits on the length of
+the command line when building many packages.
+
 When the command line specifies a single main package,
 build writes the resulting executable to output.
 Otherwise build compiles the packages but discards the results,
@@ -49,12 +55,49 @@ derives from the first file name mentioned, such as f1 for 'go build
 f1.go f2.go'; with no files provided ('go build'), the output file
 name is the base name of the containing directory.
 
+The -run flag runs the executable once it is built. It requires
+the command line to specify a single main package: the first argument
+names the package, or the leading arguments ending in .go list its
+files, and the remaining arguments are passed to the program, as in
+'go build -run ./cmd/tool arg1 arg2'.
+
+The -objdir flag keeps the compiled package archives instead of
+discarding them: each package named on the command line is compiled
+and its archive copied into the directory, at the path it would have
+under a pkg/GOOS_GOARCH directory, such as dir/my/pkg.a. Main
+packages are compiled but not linked. The flag cannot be used with
+-o or -run.
+
 The build flags are shared by the build, install, run, and test commands:
 
 	-a
 		force rebuilding of packages that are already up-to-date.
+	-cgo=false
+		disable cgo for this command, as CGO_ENABLED=0 does:
+		files that import "C" are skipped and files with a +build cgo
+		constraint are excluded, so that files with a !cgo constraint
+		are used in their place. The flag affects only the go command
+		it is given to; it does not change the environment of the
+		commands that go runs.
+	-checkimports
+		before compiling each package, parse its Go files and
+		report imports that a file does not use, failing the build
+		without running the compiler.
+	-debug-buildcontext
+		before building, print the target operating system and
+		architecture, whether cgo is enabled, the build tags, and
+		the compiler that the build uses, for debugging.
+	-generate
+		run the go.generate commands of the packages named on the
+		command line before compiling them (see below).
+	-maxmem n
+		limit linking and running the C compiler so that the
+		memory they are estimated to need stays below n bytes.
+		Other build steps still run with the parallelism set by -p.
 	-n
 		print the commands but do not run them.
+		With -v, also print for each package whether it is
+		up to date and, if not, why it must be rebuilt.
 	-p n
 		the number of builds that can be run in parallel.
 		The default is the number of CPUs available.
@@ -63,6 +106,8 @@ The build flags are shared by the build, install, run, and test commands:
 		Supported only on linux/amd64, darwin/amd64 and windows/amd64.
 	-v
 		print the names of packages as they are compiled.
+		For cgo packages, also print the generated files and
+		objects that correspond to each source file.
 	-work
 		print the name of the temporary work directory and
 		do not delete it when exiting.
@@ -71,12 +116,27 @@ The build flags are shared by the build, install, run, and test commands:
 
 	-ccflags 'arg list'
 		arguments to pass on each 5c, 6c, or 8c compiler invocation.
+	-compiledb file
+		when the command exits, write the C and C++ compiler commands run
+		for cgo packages to file as a compile_commands.json compilation
+		database, for use by clang tools and editors. Packages that
+		are up to date are not compiled, so use -a to record them all.
+		Files generated by cgo are in the work directory, which
+		-work keeps.
 	-compiler name
 		name of compiler to use, as in runtime.Compiler (gccgo or gc).
+	-debug-actiongraph file
+		when the command exits, write the graph of the build actions it
+		ran to file as JSON, for debugging. A file name of - means
+		standard output.
 	-gccgoflags 'arg list'
 		arguments to pass on each gccgo compiler/linker invocation.
 	-gcflags 'arg list'
 		arguments to pass on each 5g, 6g, or 8g compiler invocation.
+		A list of the form 'pattern=arg list' passes the arguments
+		only when compiling packages whose import paths match the
+		pattern, as in -gcflags 'mypkg/...=-N -l'. The flag may be
+		given once for all packages and once for each pattern.
 	-installsuffix suffix
 		a suffix to use in the name of the package installation directory,
 		in order to keep output separate from default builds.
@@ -85,13 +145,23 @@ The build flags are shared by the build, install, run, and test commands:
 	-ldflags 'flag list'
 		arguments to pass on each 5l, 6l, or 8l linker invocation.
 	-tags 'tag list'
-		a list of build tags to consider satisfied during the build.
-		See the documentation for the go/build package for
-		more information about build tags.
+		a list of build tags to consider satisfied during the build,
+		separated by spaces or commas. The flag may be repeated to
+		add more tags. See the documentation for the go/build package
+		for more information about build tags.
 
 The list flags accept a space-separated list of strings. To embed spaces
 in an element in the list, surround it with either single or double quotes.
 
+If the -generate flag is given and the directory of a package named
+on the command line contains a file named go.generate, each non-blank
+line of that file not beginning with # is a command to run, in the
+package directory, before compiling the package. The build then
+includes the source files the commands create. The arguments of each
+command are split like the list flags. Generated files may import only
+packages that the package's other files already import. The commands
+of dependencies are never run.
+
 For more about specifying packages, see 'go help packages'.
 For more about where packages and binaries are installed,
 run 'go help gopath'.  For more about calling between Go and C/C++,
@@ -114,9 +184,12 @@ func init() {
 var buildA bool               // -a flag
 var buildN bool               // -n flag
 var buildP = runtime.NumCPU() // -p flag
+var buildMaxmem int64         // -maxmem flag
 var buildV bool               // -v flag
 var buildX bool               // -x flag
 var buildO = cmdBuild.Flag.String("o", "", "output file")
+var buildRun = cmdBuild.Flag.Bool("run", false, "run the built executable")
+var buildObjdir = cmdBuild.Flag.String("objdir", "", "directory for compiled package archives")
 var buildWork bool           // -work flag
 var buildGcflags []string    // -gcflags flag
 var buildCcflags []string    // -ccflags flag
@@ -124,6 +197,13 @@ var buildLdflags []string    // -ldflags flag
 var buildGccgoflags []string // -gccgoflags flag
 var buildRace bool           // -race flag
 
+var buildDebugActiongraph string // -debug-actiongraph flag
+var buildDebugBuildContext bool  // -debug-buildcontext flag
+var buildCheckImports bool       // -checkimports flag
+var buildGenerate bool           // -generate flag
+var buildCompileDB string        // -compiledb flag
+var buildPkgGcflags []pkgFlags   // -gcflags pattern=flags
+
 var buildContext = build.Default
 var buildToolchain toolchain = noToolchain{}
 
@@ -164,17 +244,24 @@ func addBuildFlags(cmd *Command) {
 	cmd.Flag.BoolVar(&buildA, "a", false, "")
 	cmd.Flag.BoolVar(&buildN, "n", false, "")
 	cmd.Flag.IntVar(&buildP, "p", buildP, "")
+	cmd.Flag.Int64Var(&buildMaxmem, "maxmem", 0, "")
 	cmd.Flag.StringVar(&buildContext.InstallSuffix, "installsuffix", "", "")
 	cmd.Flag.BoolVar(&buildV, "v", false, "")
 	cmd.Flag.BoolVar(&buildX, "x", false, "")
 	cmd.Flag.BoolVar(&buildWork, "work", false, "")
-	cmd.Flag.Var((*stringsFlag)(&buildGcflags), "gcflags", "")
+	cmd.Flag.Var(gcflagsFlag{}, "gcflags", "")
 	cmd.Flag.Var((*stringsFlag)(&buildCcflags), "ccflags", "")
 	cmd.Flag.Var((*stringsFlag)(&buildLdflags), "ldflags", "")
 	cmd.Flag.Var((*stringsFlag)(&buildGccgoflags), "gccgoflags", "")
-	cmd.Flag.Var((*stringsFlag)(&buildContext.BuildTags), "tags", "")
+	cmd.Flag.Var((*tagsFlag)(&buildContext.BuildTags), "tags", "")
 	cmd.Flag.Var(buildCompiler{}, "compiler", "")
 	cmd.Flag.BoolVar(&buildRace, "race", false, "")
+	cmd.Flag.BoolVar(&buildContext.CgoEnabled, "cgo", buildContext.CgoEnabled, "")
+	cmd.Flag.BoolVar(&buildCheckImports, "checkimports", false, "")
+	cmd.Flag.BoolVar(&buildGenerate, "generate", false, "")
+	cmd.Flag.StringVar(&buildDebugActiongraph, "debug-actiongraph", "", "")
+	cmd.Flag.BoolVar(&buildDebugBuildContext, "debug-buildcontext", false, "")
+	cmd.Flag.StringVar(&buildCompileDB, "compiledb", "", "")
 }
 
 func addBuildFlagsNX(cmd *Command) {
@@ -246,14 +333,97 @@ func (v *stringsFlag) String() string {
 	return "<stringsFlag>"
 }
 
+// pkgFlags holds flags that apply only to packages
+// whose import paths match a pattern.
+type pkgFlags struct {
+	match func(string) bool
+	flags []string
+}
+
+// gcflagsFlag implements flag.Var for -gcflags.
+// A value of the form pattern=flags adds flags for the packages
+// matching pattern to buildPkgGcflags; any other value sets
+// buildGcflags, the flags for all packages.
+type gcflagsFlag struct{}
+
+func (gcflagsFlag) Set(s string) error {
+	s = strings.TrimLeft(s, " \t\n\r")
+	if i := strings.Index(s, "="); i > 0 && s[0] != '-' && !strings.ContainsAny(s[:i], " \t\n\r'\"") {
+		flags, err := splitQuotedFields(s[i+1:])
+		if err != nil {
+			return err
+		}
+		buildPkgGcflags = append(buildPkgGcflags, pkgFlags{matchPattern(s[:i]), flags})
+		return nil
+	}
+	var err error
+	buildGcflags, err = splitQuotedFields(s)
+	return err
+}
+
+func (gcflagsFlag) String() string {
+	return "<gcflagsFlag>"
+}
+
+// gcflagsFor returns the -gcflags arguments that apply to p
+// in addition to buildGcflags.
+func gcflagsFor(p *Package) []string {
+	var flags []string
+	for _, pf := range buildPkgGcflags {
+		if pf.match(p.ImportPath) {
+			flags = append(flags, pf.flags...)
+		}
+	}
+	return flags
+}
+
+// tagsFlag is the type of the -tags flag. Each use of the flag adds
+// its tags, separated by spaces or commas, to the list; tags already
+// in the list are not added again.
+type tagsFlag []string
+
+func (v *tagsFlag) Set(s string) error {
+Tags:
+	for _, tag := range strings.FieldsFunc(s, isTagSep) {
+		for _, t := range *v {
+			if t == tag {
+				continue Tags
+			}
+		}
+		*v = append(*v, tag)
+	}
+	return nil
+}
+
+func (v *tagsFlag) String() string {
+	return "<tagsFlag>"
+}
+
+func isTagSep(r rune) bool {
+	return r == ',' || r < 0x80 && isSpaceByte(byte(r))
+}
+
 func runBuild(cmd *Command, args []string) {
 	raceInit()
+	var runArgs []string
+	if *buildRun {
+		args, runArgs = splitRunArgs(args)
+	}
+	args = stdinImportPaths(args)
 	var b builder
 	b.init()
 
 	pkgs := packagesForBuild(args)
 
-	if len(pkgs) == 1 && pkgs[0].Name == "main" && *buildO == "" {
+	if *buildRun && (len(pkgs) != 1 || pkgs[0].Name != "main") {
+		fatalf("go build: -run requires a single main package")
+	}
+
+	if *buildObjdir != "" && (*buildO != "" || *buildRun) {
+		fatalf("go build: cannot use -objdir with -o or -run")
+	}
+
+	if len(pkgs) == 1 && pkgs[0].Name == "main" && *buildO == "" && *buildObjdir == "" {
 		_, *buildO = path.Split(pkgs[0].ImportPath)
 		*buildO += exeSuffix
 	}
@@ -261,7 +431,7 @@ func runBuild(cmd *Command, args []string) {
 	// sanity check some often mis-used options
 	switch buildContext.Compiler {
 	case "gccgo":
-		if len(buildGcflags) != 0 {
+		if len(buildGcflags) != 0 || len(buildPkgGcflags) != 0 {
 			fmt.Println("go build: when using gccgo toolchain, please pass compiler flags using -gccgoflags, not -gcflags")
 		}
 		if len(buildLdflags) != 0 {
@@ -277,10 +447,56 @@ func runBuild(cmd *Command, args []string) {
 		if len(pkgs) > 1 {
 			fatalf("go build: cannot use -o with multiple packages")
 		}
+		// Check the output directory now rather than after
+		// building everything.
+		if dir := filepath.Dir(*buildO); dir != "." {
+			fi, err := os.Stat(dir)
+			switch {
+			case os.IsNotExist(err):
+				fatalf("go build: cannot write %s: directory %s does not exist", *buildO, dir)
+			case err != nil:
+				fatalf("go build: cannot write %s: %v", *buildO, err)
+			case !fi.IsDir():
+				fatalf("go build: cannot write %s: %s is not a directory", *buildO, dir)
+			}
+		}
 		p := pkgs[0]
 		p.target = "" // must build - not up to date
 		a := b.action(modeInstall, modeBuild, p)
 		a.target = *buildO
+		if *buildRun {
+			// Run the executable by its full path, since a bare
+			// name would be looked up in $PATH.
+			if !filepath.IsAbs(a.target) {
+				a.target = filepath.Join(cwd, a.target)
+			}
+			a = &action{f: (*builder).runProgram, args: runArgs, deps: []*action{a}}
+		}
+		b.do(a)
+		return
+	}
+
+	if *buildObjdir != "" {
+		for _, p := range pkgs {
+			p.target = "" // must build - not up to date
+		}
+		a := &action{}
+		for _, p := range pkgs {
+			a1 := b.action(modeBuild, modeBuild, p)
+			if a1.objpkg == "" {
+				// Fake package - nothing to copy.
+				continue
+			}
+			// Keep only the archive, even for a main package.
+			a1.link = false
+			a1.target = a1.objpkg
+			a.deps = append(a.deps, &action{
+				p:      p,
+				f:      (*builder).copyObj,
+				deps:   []*action{a1},
+				target: buildToolchain.pkgpath(*buildObjdir, p),
+			})
+		}
 		b.do(a)
 		return
 	}
@@ -292,12 +508,27 @@ func runBuild(cmd *Command, args []string) {
 	b.do(a)
 }
 
+// splitRunArgs splits the arguments to go build -run into those naming
+// the package to build and those to pass to the program: either the
+// leading .go files or, if there are none, the first argument.
+func splitRunArgs(args []string) (pkgArgs, runArgs []string) {
+	i := 0
+	for i < len(args) && strings.HasSuffix(args[i], ".go") {
+		i++
+	}
+	if i == 0 && len(args) > 0 {
+		i = 1
+	}
+	return args[:i], args[i:]
+}
+
 var cmdInstall = &Command{
 	UsageLine: "install [build flags] [packages]",
 	Short:     "compile and install packages and dependencies",
 	Long: `
 Install compiles and installs the packages named by the import paths,
-along with their dependencies.
+along with their dependencies. As with build, an argument of - reads
+import paths from standard input.
 
 For more about the build flags, see 'go help build'.
 For more about specifying packages, see 'go help packages'.
@@ -308,6 +539,7 @@ See also: go build, go get, go clean.
 
 func runInstall(cmd *Command, args []string) {
 	raceInit()
+	args = stdinImportPaths(args)
 	pkgs := packagesForBuild(args)
 
 	for _, p := range pkgs {
@@ -359,6 +591,7 @@ func init() {
 type builder struct {
 	work        string               // the temporary work directory (ends in filepath.Separator)
 	actionCache map[cacheKey]*action // a cache of already-constructed actions
+	genCache    map[string]*action   // go.generate actions, by package directory
 	mkdirCache  map[string]bool      // a cache of created directories
 	print       func(args ...interface{}) (int, error)
 
@@ -368,11 +601,66 @@ type builder struct {
 	exec      sync.Mutex
 	readySema chan bool
 	ready     actionQueue
+	mem       *memSema // limits memory-heavy steps; nil unless -maxmem is set
+}
+
+// Estimated memory needed by the steps limited by -maxmem.
+const (
+	gccMem  = 256 << 20 // running gcc on a package's C files
+	gxxMem  = 512 << 20 // running g++ on a package's C++ files
+	linkMem = 512 << 20 // linking an executable
+)
+
+// A memSema is a weighted semaphore bounding the total memory
+// estimated to be in use by concurrently running build steps.
+// The methods of a nil *memSema do nothing.
+type memSema struct {
+	mu    sync.Mutex
+	cond  sync.Cond
+	avail int64
+	max   int64
+}
+
+func newMemSema(max int64) *memSema {
+	s := &memSema{avail: max, max: max}
+	s.cond.L = &s.mu
+	return s
+}
+
+// acquire waits until n bytes are available and takes them.
+// It returns the amount actually taken, which is smaller than n
+// if n exceeds the limit, so that a single large step can still run.
+func (s *memSema) acquire(n int64) int64 {
+	if s == nil {
+		return 0
+	}
+	if n > s.max {
+		n = s.max
+	}
+	s.mu.Lock()
+	for s.avail < n {
+		s.cond.Wait()
+	}
+	s.avail -= n
+	s.mu.Unlock()
+	return n
+}
+
+// release returns n bytes taken by acquire.
+func (s *memSema) release(n int64) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	s.avail += n
+	s.mu.Unlock()
+	s.cond.Broadcast()
 }
 
 // An action represents a single action in the action graph.
 type action struct {
 	p          *Package      // the package this action works on
+	mode       buildMode     // whether the package is built or installed
 	deps       []*action     // actions that must happen before this one
 	triggers   []*action     // inverse of deps
 	cgo        *action       // action for cgo binary if needed
@@ -410,6 +698,16 @@ const (
 	modeInstall
 )
 
+func (m buildMode) String() string {
+	switch m {
+	case modeBuild:
+		return "build"
+	case modeInstall:
+		return "install"
+	}
+	return fmt.Sprintf("buildMode(%d)", int(m))
+}
+
 var (
 	goroot       = filepath.Clean(runtime.GOROOT())
 	gobin        = os.Getenv("GOBIN")
@@ -425,6 +723,7 @@ func (b *builder) init() {
 		return fmt.Fprint(os.Stderr, a...)
 	}
 	b.actionCache = make(map[cacheKey]*action)
+	b.genCache = make(map[string]*action)
 	b.mkdirCache = make(map[string]bool)
 
 	if buildN {
@@ -441,6 +740,37 @@ func (b *builder) init() {
 			atexit(func() { os.RemoveAll(b.work) })
 		}
 	}
+
+	if buildCompileDB != "" && !buildN && !compileDB.pending {
+		compileDB.pending = true
+		atexit(writeCompileDB)
+	}
+
+	if buildDebugBuildContext {
+		b.showBuildContext()
+	}
+}
+
+// showBuildContext prints the settings of buildContext and
+// buildToolchain that decide which files are built and how,
+// for the -debug-buildcontext flag.
+func (b *builder) showBuildContext() {
+	cgo := "0"
+	if buildContext.CgoEnabled {
+		cgo = "1"
+	}
+	compiler := buildContext.Compiler
+	if _, ok := buildToolchain.(noToolchain); !ok {
+		compiler += " " + buildToolchain.compiler()
+	}
+	fmt.Fprintf(os.Stderr, "GOOS=%s\n", buildContext.GOOS)
+	fmt.Fprintf(os.Stderr, "GOARCH=%s\n", buildContext.GOARCH)
+	fmt.Fprintf(os.Stderr, "CGO_ENABLED=%s\n", cgo)
+	fmt.Fprintf(os.Stderr, "tags=%s\n", strings.Join(buildContext.BuildTags, " "))
+	if buildContext.InstallSuffix != "" {
+		fmt.Fprintf(os.Stderr, "installsuffix=%s\n", buildContext.InstallSuffix)
+	}
+	fmt.Fprintf(os.Stderr, "compiler=%s\n", compiler)
 }
 
 // goFilesPackage creates a package for building a collection of Go files
@@ -511,7 +841,9 @@ func goFilesPackage(gofiles []string) *Package {
 	}
 	pkg.Target = pkg.target
 	pkg.Stale = true
+	pkg.staleReason = "built from files named on the command line"
 
+	loadGenerate([]*Package{pkg})
 	computeStale(pkg)
 	return pkg
 }
@@ -525,7 +857,7 @@ func (b *builder) action(mode buildMode, depMode buildMode, p *Package) *action
 		return a
 	}
 
-	a = &action{p: p, pkgdir: p.build.PkgRoot}
+	a = &action{p: p, mode: mode, pkgdir: p.build.PkgRoot}
 	if p.pkgdir != "" { // overrides p.t
 		a.pkgdir = p.pkgdir
 	}
@@ -594,6 +926,9 @@ func (b *builder) action(mode buildMode, depMode buildMode, p *Package) *action
 	case modeBuild:
 		a.f = (*builder).build
 		a.target = a.objpkg
+		if p.generate != nil {
+			a.deps = append(a.deps, b.generateAction(p))
+		}
 		if a.link {
 			// An executable file. (This is the name of a temporary file.)
 			// Because we run the temporary file in 'go run' and 'go test',
@@ -613,6 +948,29 @@ func (b *builder) action(mode buildMode, depMode buildMode, p *Package) *action
 	return a
 }
 
+// explainStale prints, for each package in the list of actions, whether
+// it is up to date or why it must be rebuilt. It is used by -n -v.
+func (b *builder) explainStale(all []*action) {
+	seen := map[*Package]bool{}
+	for _, a := range all {
+		p := a.p
+		if p == nil || seen[p] {
+			continue
+		}
+		seen[p] = true
+		switch {
+		case p.Standard && (p.ImportPath == "builtin" || p.ImportPath == "unsafe"):
+			// Fake packages - nothing to build.
+		case !p.Stale:
+			b.print("# " + p.ImportPath + ": up to date\n")
+		case p.staleReason == "":
+			b.print("# " + p.ImportPath + ": stale\n")
+		default:
+			b.print("# " + p.ImportPath + ": stale: " + p.staleReason + "\n")
+		}
+	}
+}
+
 // actionList returns the list of actions in the dag rooted at root
 // as visited in a depth-first post-order traversal.
 func actionList(root *action) []*action {
@@ -633,6 +991,83 @@ func actionList(root *action) []*action {
 	return all
 }
 
+// An actionJSON is the JSON form of an action written by -debug-actiongraph.
+type actionJSON struct {
+	ID          int
+	Priority    int
+	Mode        string   `json:",omitempty"`
+	Package     string   `json:",omitempty"`
+	Target      string   `json:",omitempty"`
+	Objpkg      string   `json:",omitempty"`
+	Objdir      string   `json:",omitempty"`
+	Link        bool     `json:",omitempty"`
+	Deps        []int    `json:",omitempty"`
+	DepPackages []string `json:",omitempty"`
+}
+
+// actionGraph accumulates the actions run by all calls to do,
+// for -debug-actiongraph.
+var actionGraph struct {
+	id   map[*action]int
+	list []*actionJSON
+}
+
+// recordActionGraph adds the actions in all, which must be the
+// result of actionList with priorities assigned, to actionGraph.
+// The first call arranges for the graph to be written when the
+// command exits.
+func recordActionGraph(all []*action) {
+	if actionGraph.id == nil {
+		actionGraph.id = make(map[*action]int)
+		atexit(writeActionGraph)
+	}
+	for _, a := range all {
+		if _, ok := actionGraph.id[a]; ok {
+			// Already run by an earlier call to do.
+			continue
+		}
+		j := &actionJSON{
+			ID:       len(actionGraph.list),
+			Priority: a.priority,
+			Target:   a.target,
+			Objpkg:   a.objpkg,
+			Objdir:   a.objdir,
+			Link:     a.link,
+		}
+		if a.p != nil {
+			j.Mode = a.mode.String()
+			j.Package = a.p.ImportPath
+		}
+		for _, a1 := range a.deps {
+			j.Deps = append(j.Deps, actionGraph.id[a1])
+			if a1.p != nil {
+				j.DepPackages = append(j.DepPackages, a1.p.ImportPath)
+			}
+		}
+		actionGraph.id[a] = j.ID
+		actionGraph.list = append(actionGraph.list, j)
+	}
+}
+
+// writeActionGraph writes actionGraph to the file named by the
+// -debug-actiongraph flag. It runs when the command exits, so
+// it reports errors without exiting.
+func writeActionGraph() {
+	data, err := json.MarshalIndent(actionGraph.list, "", "\t")
+	if err != nil {
+		errorf("go: writing action graph: %v", err)
+		return
+	}
+	data = append(data, '\n')
+	if buildDebugActiongraph == "-" {
+		os.Stdout.Write(data)
+		return
+	}
+	if err := ioutil.WriteFile(buildDebugActiongraph, data, 0666); err != nil {
+		errorf("go: writing action graph: %v", err)
+	}
+}
+
 // do runs the action graph rooted at root.
 func (b *builder) do(root *action) {
 	// Build list of all actions, assigning depth-first post-order priority.
@@ -650,8 +1085,18 @@ func (b *builder) do(root *action) {
 	for i, a := range all {
 		a.priority = i
 	}
+	if buildDebugActiongraph != "" {
+		recordActionGraph(all)
+	}
+	if buildN && buildV {
+		b.explainStale(all)
+	}
+	checkInstallTargets(all)
 
 	b.readySema = make(chan bool, len(all))
+	if buildMaxmem > 0 {
+		b.mem = newMemSema(buildMaxmem)
+	}
 
 	// Initialize per-action execution state.
 	for _, a := range all {
@@ -739,6 +1184,80 @@ func (b *builder) do(root *action) {
 	wg.Wait()
 }
 
+// checkInstallTargets exits with an error if two of the install actions
+// in all would write the same target file for different packages,
+// since one would silently overwrite the other.
+func checkInstallTargets(all []*action) {
+	installer := make(map[string]*Package)
+	for _, a := range all {
+		if a.mode != modeInstall || a.f == nil || a.p == nil || a.target == "" {
+			continue
+		}
+		if p := installer[a.target]; p != nil && p.ImportPath != a.p.ImportPath {
+			fatalf("go: %s and %s both install to %s", p.ImportPath, a.p.ImportPath, a.target)
+		}
+		installer[a.target] = a.p
+	}
+}
+
+// A compileCommand is an entry in the compilation database written
+// by -compiledb, in the format read by clang tools.
+type compileCommand struct {
+	Directory string   `json:"directory"`
+	File      string   `json:"file"`
+	Output    string   `json:"output"`
+	Arguments []string `json:"arguments"`
+}
+
+type compileCommandsByFile []compileCommand
+
+func (x compileCommandsByFile) Len() int           { return len(x) }
+func (x compileCommandsByFile) Swap(i, j int)      { x[i], x[j] = x[j], x[i] }
+func (x compileCommandsByFile) Less(i, j int) bool { return x[i].File < x[j].File }
+
+// compileDB accumulates the compilations recorded by ccompile
+// in all builds run by the command, for -compiledb.
+var compileDB struct {
+	sync.Mutex
+	list    []compileCommand
+	pending bool // writeCompileDB will run at exit
+}
+
+// writeCompileDB writes compileDB to the file named by the -compiledb
+// flag. It runs when the command exits, so it reports errors without
+// exiting.
+func writeCompileDB() {
+	compileDB.Lock()
+	list := compileDB.list
+	compileDB.Unlock()
+	if list == nil {
+		list = []compileCommand{}
+	}
+	sort.Stable(compileCommandsByFile(list))
+	data, err := json.MarshalIndent(list, "", "\t")
+	if err != nil {
+		errorf("go: writing compilation database: %v", err)
+		return
+	}
+	data = append(data, '\n')
+	if buildCompileDB == "-" {
+		os.Stdout.Write(data)
+		return
+	}
+	if err := ioutil.WriteFile(buildCompileDB, data, 0666); err != nil {
+		errorf("go: writing compilation database: %v", err)
+	}
+}
+
+// gccMemNeeded returns the memory estimated to be needed
+// to run the C and C++ compilers on p.
+func gccMemNeeded(p *Package) int64 {
+	if len(p.CXXFiles) > 0 || len(p.SwigCXXFiles) > 0 {
+		return gxxMem
+	}
+	return gccMem
+}
+
 // hasString reports whether s appears in the list of strings.
 func hasString(strings []string, s string) bool {
 	for _, t := range strings {
@@ -797,6 +1316,18 @@ func (b *builder) build(a *action) (err error) {
 		}
 	}
 
+	if a.p.generate != nil && !buildN {
+		if err := a.p.addGenerated(); err != nil {
+			return err
+		}
+	}
+
+	if buildCheckImports {
+		if err := b.checkImports(a.p); err != nil {
+			return err
+		}
+	}
+
 	var gofiles, cfiles, sfiles, objects, cgoObjects []string
 
 	// If we're doing coverage, preprocess the .go files and put them in the work directory
@@ -821,6 +1352,14 @@ func (b *builder) build(a *action) (err error) {
 	cfiles = append(cfiles, a.p.CFiles...)
 	sfiles = append(sfiles, a.p.SFiles...)
 
+	// .S files need the C preprocessor. In packages using cgo or
+	// SWIG gcc compiles them, and gccgo assembles them itself.
+	// The gc assemblers cannot, so they are not used otherwise.
+	if _, ok := buildToolchain.(gccgoToolchain); ok || a.p.usesCgo() || a.p.usesSwig() {
+		sfiles = append(sfiles, a.p.CapSFiles...)
+		sort.Strings(sfiles)
+	}
+
 	// Run cgo.
 	if a.p.usesCgo() {
 		// In a package using cgo, cgo compiles the C, C++ and assembly files with gcc.
@@ -851,7 +1390,14 @@ func (b *builder) build(a *action) (err error) {
 		if a.cgo != nil && a.cgo.target != "" {
 			cgoExe = a.cgo.target
 		}
-		outGo, outObj, err := b.cgo(a.p, cgoExe, obj, gccfiles, a.p.CXXFiles)
+		// Objective-C files are only compiled for darwin.
+		var mfiles []string
+		if goos == "darwin" {
+			mfiles = a.p.MFiles
+		}
+		mem := b.mem.acquire(gccMemNeeded(a.p))
+		outGo, outObj, err := b.cgo(a.p, cgoExe, obj, gccfiles, a.p.CXXFiles, mfiles)
+		b.mem.release(mem)
 		if err != nil {
 			return err
 		}
@@ -866,7 +1412,9 @@ func (b *builder) build(a *action) (err error) {
 		gccfiles := append(cfiles, sfiles...)
 		cfiles = nil
 		sfiles = nil
+		mem := b.mem.acquire(gccMemNeeded(a.p))
 		outGo, outObj, err := b.swig(a.p, obj, gccfiles, a.p.CXXFiles)
+		b.mem.release(mem)
 		if err != nil {
 			return err
 		}
@@ -963,7 +1511,10 @@ func (b *builder) build(a *action) (err error) {
 		// linker needs the whole dependency tree.
 		all := actionList(a)
 		all = all[:len(all)-1] // drop a
-		if err := buildToolchain.ld(b, a.p, a.target, all, a.objpkg, objects); err != nil {
+		mem := b.mem.acquire(linkMem)
+		err := buildToolchain.ld(b, a.p, a.target, all, a.objpkg, objects)
+		b.mem.release(mem)
+		if err != nil {
 			return err
 		}
 	}
@@ -1019,6 +1570,20 @@ func (b *builder) install(a *action) (err error) {
 	return b.copyFile(a, a.target, a1.target, perm)
 }
 
+// copyObj is the action for copying the archive of a single package
+// to the directory given by the -objdir flag.
+func (b *builder) copyObj(a *action) error {
+	a1 := a.deps[0]
+	dir, _ := filepath.Split(a.target)
+	if err := b.mkdir(dir); err != nil {
+		return err
+	}
+	if err := b.copyFile(a, a.target, a1.objpkg, 0666); err != nil {
+		return fmt.Errorf("go build %s: %v", a.p.ImportPath, err)
+	}
+	return nil
+}
+
 // includeArgs returns the -I or -L directory list for access
 // to the results of the list of actions.
 func (b *builder) includeArgs(flag string, all []*action) []string {
@@ -1533,7 +2098,7 @@ func (gcToolchain) gc(b *builder, p *Package, obj string, importArgs []string, g
 		gcargs = append(gcargs, "-installsuffix", buildContext.InstallSuffix)
 	}
 
-	args := stringList(tool(archChar+"g"), "-o", ofile, buildGcflags, gcargs, "-D", p.localPrefix, importArgs)
+	args := stringList(tool(archChar+"g"), "-o", ofile, buildGcflags, gcflagsFor(p), gcargs, "-D", p.localPrefix, importArgs)
 	for _, f := range gofiles {
 		args = append(args, mkAbs(p.Dir, f))
 	}
@@ -1829,6 +2394,16 @@ func (b *builder) gxx(p *Package, out string, flags []string, cxxfile string) er
 // ccompile runs the given C or C++ compiler and creates an object from a single source file.
 func (b *builder) ccompile(p *Package, out string, flags []string, file string, compiler []string) error {
 	file = mkAbs(p.Dir, file)
+	if buildCompileDB != "" {
+		compileDB.Lock()
+		compileDB.list = append(compileDB.list, compileCommand{
+			Directory: p.Dir,
+			File:      file,
+			Output:    out,
+			Arguments: stringList(compiler, flags, "-o", out, "-c", file),
+		})
+		compileDB.Unlock()
+	}
 	return b.run(p.Dir, p.ImportPath, nil, compiler, flags, "-o", out, "-c", file)
 }
 
@@ -1846,22 +2421,38 @@ func (b *builder) gccld(p *Package, out string, flags []string, obj []string) er
 // gccCmd returns a gcc command line prefix
 // defaultCC is defined in zdefaultcc.go, written by cmd/dist.
 func (b *builder) gccCmd(objdir string) []string {
-	return b.ccompilerCmd("CC", defaultCC, objdir)
+	return b.ccompilerCmd("CC", "CC_FOR_TARGET", defaultCC, objdir)
 }
 
 // gxxCmd returns a g++ command line prefix
 // defaultCXX is defined in zdefaultcc.go, written by cmd/dist.
 func (b *builder) gxxCmd(objdir string) []string {
-	return b.ccompilerCmd("CXX", defaultCXX, objdir)
+	return b.ccompilerCmd("CXX", "CXX_FOR_TARGET", defaultCXX, objdir)
+}
+
+// crossCompiling reports whether the build is for a different
+// operating system or architecture than the go command's own.
+func crossCompiling() bool {
+	return goos != toolGOOS || goarch != toolGOARCH
 }
 
 // ccompilerCmd returns a command line prefix for the given environment
-// variable and using the default command when the variable is empty
-func (b *builder) ccompilerCmd(envvar, defcmd, objdir string) []string {
+// variable and using the default command when the variable is empty.
+// When cross-compiling, the compiler named by the targetvar environment
+// variable takes precedence; it is assumed to already generate code for
+// the target architecture.
+func (b *builder) ccompilerCmd(envvar, targetvar, defcmd, objdir string) []string {
 	// NOTE: env.go's mkEnv knows that the first three
 	// strings returned are "gcc", "-I", objdir (and cuts them off).
 
-	compiler := strings.Fields(os.Getenv(envvar))
+	var compiler []string
+	if crossCompiling() {
+		compiler = strings.Fields(os.Getenv(targetvar))
+	}
+	targetCompiler := len(compiler) > 0
+	if len(compiler) == 0 {
+		compiler = strings.Fields(os.Getenv(envvar))
+	}
 	if len(compiler) == 0 {
 		compiler = strings.Fields(defcmd)
 	}
@@ -1873,7 +2464,9 @@ func (b *builder) ccompilerCmd(envvar, defcmd, objdir string) []string {
 	if goos != "windows" {
 		a = append(a, "-fPIC")
 	}
-	a = append(a, b.gccArchArgs()...)
+	if !targetCompiler {
+		a = append(a, b.gccArchArgs()...)
+	}
 	// gcc-4.5 and beyond require explicit "-pthread" flag
 	// for multithreading with pthread library.
 	if buildContext.CgoEnabled {
@@ -1927,7 +2520,30 @@ var (
 	cgoLibGccFileOnce sync.Once
 )
 
-func (b *builder) cgo(p *Package, cgoExe, obj string, gccfiles []string, gxxfiles []string) (outGo, outObj []string, err error) {
+// showCgoFiles prints, for -v, the names of the files that cgo
+// generates from each of p's cgo files and the objects that gcc
+// produces for each C source file, so that errors in generated code
+// can be traced back to their source.  It matches the names used
+// by builder.cgo.
+func (b *builder) showCgoFiles(p *Package, obj string, gccfiles, gxxfiles, mfiles []string) {
+	var buf bytes.Buffer
+	for _, fn := range p.CgoFiles {
+		f := cgoRe.ReplaceAllString(fn[:len(fn)-2], "_")
+		fmt.Fprintf(&buf, "%s -> %s %s\n", fn, obj+f+"cgo1.go", obj+f+"cgo2.o")
+	}
+	for _, file := range gccfiles {
+		fmt.Fprintf(&buf, "%s -> %s\n", file, obj+cgoRe.ReplaceAllString(file[:len(file)-1], "_")+"o")
+	}
+	for _, file := range gxxfiles {
+		fmt.Fprintf(&buf, "%s -> %s\n", file, obj+cgoRe.ReplaceAllString(file, "_")+".o")
+	}
+	for _, file := range mfiles {
+		fmt.Fprintf(&buf, "%s -> %s\n", file, obj+cgoRe.ReplaceAllString(file, "_")+".o")
+	}
+	b.showOutput(p.Dir, p.ImportPath+" (cgo files)", buf.String())
+}
+
+func (b *builder) cgo(p *Package, cgoExe, obj string, gccfiles, gxxfiles, mfiles []string) (outGo, outObj []string, err error) {
 	if goos != toolGOOS {
 		return nil, nil, errors.New("cannot use cgo when compiling for a different operating system")
 	}
@@ -1993,6 +2609,10 @@ func (b *builder) cgo(p *Package, cgoExe, obj string, gccfiles []string, gxxfile
 		}
 		cgoenv = []string{"CGO_LDFLAGS=" + strings.Join(flags, " ")}
 	}
+	// cgo runs the C compiler itself; have it use the target's.
+	if crossCompiling() && os.Getenv("CC_FOR_TARGET") != "" {
+		cgoenv = append(cgoenv, "CC="+os.Getenv("CC_FOR_TARGET"))
+	}
 
 	if _, ok := buildToolchain.(gccgoToolchain); ok {
 		cgoflags = append(cgoflags, "-gccgo")
@@ -2001,6 +2621,26 @@ func (b *builder) cgo(p *Package, cgoExe, obj string, gccfiles []string, gxxfile
 		}
 		objExt = "o"
 	}
+
+	if buildV {
+		b.showCgoFiles(p, obj, gccfiles, gxxfiles, mfiles)
+	}
+
+	// Reuse the outputs of an earlier identical run, if cached.
+	var cacheKey string
+	if !buildN {
+		cacheKey = b.cgoCacheKey(p, cgoExe, obj, gccfiles, gxxfiles, mfiles,
+			stringList(cgoCPPFLAGS, cgoCFLAGS), stringList(cgoCPPFLAGS, cgoCXXFLAGS),
+			cgoflags, cgoLDFLAGS)
+		// A cached result would leave out the C compilations
+		// that -compiledb is to record.
+		if !buildA && buildCompileDB == "" {
+			if outGo, outObj, ok := b.cgoCacheGet(cacheKey, obj); ok {
+				return outGo, outObj, nil
+			}
+		}
+	}
+
 	if err := b.run(p.Dir, p.ImportPath, cgoenv, cgoExe, "-objdir", obj, cgoflags, "--", cgoCPPFLAGS, cgoCFLAGS, p.CgoFiles); err != nil {
 		return nil, nil, err
 	}
@@ -2088,6 +2728,17 @@ func (b *builder) cgo(p *Package, cgoExe, obj string, gccfiles []string, gxxfile
 		outObj = append(outObj, ofile)
 	}
 
+	mflags := stringList(cflags, "-x", "objective-c")
+	for _, file := range mfiles {
+		// Append .o to the file, as for C++, in case the pkg has file.c and file.m
+		ofile := obj + cgoRe.ReplaceAllString(file, "_") + ".o"
+		if err := b.gcc(p, ofile, mflags, file); err != nil {
+			return nil, nil, err
+		}
+		linkobj = append(linkobj, ofile)
+		outObj = append(outObj, ofile)
+	}
+
 	linkobj = append(linkobj, p.SysoFiles...)
 	dynobj := obj + "_cgo_.o"
 	if goarch == "arm" && goos == "linux" { // we need to use -pie for Linux/ARM to get accurate imported sym
@@ -2102,6 +2753,7 @@ func (b *builder) cgo(p *Package, cgoExe, obj string, gccfiles []string, gxxfile
 
 	if _, ok := buildToolchain.(gccgoToolchain); ok {
 		// we don't use dynimport when using gccgo.
+		b.cgoCachePut(cacheKey, obj, outGo, outObj)
 		return outGo, outObj, nil
 	}
 
@@ -2139,6 +2791,7 @@ func (b *builder) cgo(p *Package, cgoExe, obj string, gccfiles []string, gxxfile
 	// Put it first.  http://golang.org/issue/2601
 	outObj = stringList(importObj, nonGccObjs, ofile)
 
+	b.cgoCachePut(cacheKey, obj, outGo, outObj)
 	return outGo, outObj, nil
 }
 
@@ -0,0 +1,399 @@
+// Copyright 2013 The Go Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !cmd_go_bootstrap
+
+// The bootstrap 'go' binary has no cgo cache (see bootstrap.go),
+// so that building it does not need crypto/sha1.
+
+package main
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"hash"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The cgo cache holds the Go files and objects generated by running
+// cgo and the C compiler on a package, so that rebuilding an unchanged
+// cgo package can skip those steps.  Each entry is a directory named by
+// a hash of everything that went into producing it (see cgoCacheKey),
+// holding the generated files and a manifest listing them.  Entries
+// that have not been used for cgoCacheTrimAge are removed (see
+// cgoCacheTrim).
+
+const (
+	// cgoCacheTrimAge is how long an unused entry stays in the cache.
+	cgoCacheTrimAge = 5 * 24 * time.Hour
+	// cgoCacheTrimInterval is how often the cache is scanned
+	// for entries to remove.
+	cgoCacheTrimInterval = 24 * time.Hour
+	// cgoCacheTouchInterval is how stale the recorded last use
+	// of an entry may get before a hit updates it.
+	cgoCacheTouchInterval = time.Hour
+)
+
+// cgoCacheDir returns the directory holding the cgo cache,
+// or the empty string if the cache is disabled.
+// $GOCGOCACHE overrides the default location; setting it
+// to "off" disables the cache.
+func cgoCacheDir() string {
+	if dir := os.Getenv("GOCGOCACHE"); dir != "" {
+		if dir == "off" {
+			return ""
+		}
+		return dir
+	}
+	var base string
+	switch runtime.GOOS {
+	case "windows":
+		base = os.Getenv("LocalAppData")
+	case "darwin":
+		if home := os.Getenv("HOME"); home != "" {
+			base = filepath.Join(home, "Library", "Caches")
+		}
+	case "plan9":
+		if home := os.Getenv("home"); home != "" {
+			base = filepath.Join(home, "lib", "cache")
+		}
+	default:
+		base = os.Getenv("XDG_CACHE_HOME")
+		if base == "" {
+			if home := os.Getenv("HOME"); home != "" {
+				base = filepath.Join(home, ".cache")
+			}
+		}
+	}
+	if base == "" {
+		return ""
+	}
+	return filepath.Join(base, "go-cgo")
+}
+
+// cgoCacheKey returns the cache key for running cgoExe and the C
+// compilers on p with the given flags and input files.  It covers the
+// tools themselves, the C compiler command lines, the content of every
+// input file, and the content of the headers outside the package
+// directory that the inputs include (see cgoHeaders).  The C and C++
+// files are compiled with cflags and cxxflags; the cgo files' preambles
+// and the Objective-C files with cflags.  References to the object
+// directory obj are left out, since it changes with every build.  If an
+// input cannot be read, cgoCacheKey returns the empty string and the
+// cache is not used.
+func (b *builder) cgoCacheKey(p *Package, cgoExe, obj string, gccfiles, gxxfiles, mfiles, cflags, cxxflags []string, flags ...[]string) string {
+	if cgoCacheDir() == "" {
+		return ""
+	}
+	headers, ok := b.cgoHeaders(p, obj, gccfiles, gxxfiles, mfiles, cflags, cxxflags)
+	if !ok {
+		return ""
+	}
+	flags = append(flags, cflags, cxxflags)
+	gcc, gxx := b.gccCmd(p.Dir), b.gxxCmd(p.Dir)
+	tools := []string{cgoExe}
+	for _, cmd := range []string{gcc[0], gxx[0]} {
+		if path, err := exec.LookPath(cmd); err == nil {
+			tools = append(tools, path)
+		}
+	}
+	if _, ok := buildToolchain.(gccgoToolchain); ok {
+		tools = append(tools, gccgoBin)
+		flags = append(flags, buildGccgoflags)
+	} else {
+		// The gc C compiler also reads the runtime headers
+		// installed in $GOROOT/pkg/$GOOS_$GOARCH.
+		tools = append(tools, tool(archChar+"c"))
+		inc, _ := filepath.Glob(filepath.Join(goroot, "pkg", goos+"_"+goarch, "*.h"))
+		tools = append(tools, inc...)
+		flags = append(flags, buildCcflags)
+	}
+	flags = append(flags, gcc, gxx)
+
+	h := sha1.New()
+	fmt.Fprintf(h, "goos %s goarch %s compiler %s\n", goos, goarch, buildContext.Compiler)
+	fmt.Fprintf(h, "package %s %s\n", p.ImportPath, p.Dir)
+	for _, t := range tools {
+		if !hashFile(h, "tool "+t, t) {
+			return ""
+		}
+	}
+	for _, list := range flags {
+		fmt.Fprintf(h, "flags")
+		for _, f := range list {
+			if f == obj {
+				f = "$OBJ"
+			}
+			fmt.Fprintf(h, " %q", f)
+		}
+		fmt.Fprintf(h, "\n")
+	}
+	for _, files := range [][]string{p.CgoFiles, gccfiles, gxxfiles, mfiles, p.HFiles, p.SysoFiles} {
+		for _, file := range files {
+			if !hashFile(h, "file "+file, mkAbs(p.Dir, file)) {
+				return ""
+			}
+		}
+	}
+	for _, file := range headers {
+		if !hashFile(h, "header "+file, file) {
+			return ""
+		}
+	}
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
+
+// cgoHeaders returns the sorted list of headers outside the package
+// directory that p's cgo preambles and C, C++ and Objective-C files
+// include, directly or indirectly, as reported by the C compilers'
+// -M option.  Headers that do not exist yet, such as _cgo_export.h,
+// are generated by cgo and left out.  The preambles are written to
+// files in obj for the compiler to read.  The result reports whether
+// the headers could be determined.
+func (b *builder) cgoHeaders(p *Package, obj string, gccfiles, gxxfiles, mfiles, cflags, cxxflags []string) ([]string, bool) {
+	var cfiles []string
+	fset := token.NewFileSet()
+	for _, file := range p.CgoFiles {
+		f, err := parser.ParseFile(fset, mkAbs(p.Dir, file), nil, parser.ImportsOnly|parser.ParseComments)
+		if err != nil {
+			return nil, false
+		}
+		preamble := obj + "_cgo_deps_" + cgoRe.ReplaceAllString(file[:len(file)-2], "_") + "c"
+		if err := ioutil.WriteFile(preamble, cgoPreamble(f), 0666); err != nil {
+			return nil, false
+		}
+		cfiles = append(cfiles, preamble)
+	}
+	cfiles = append(cfiles, gccfiles...)
+
+	seen := make(map[string]bool)
+	for _, run := range []struct {
+		cmd   []string
+		flags []string
+		files []string
+	}{
+		{b.gccCmd(obj), cflags, cfiles},
+		{b.gxxCmd(obj), cxxflags, gxxfiles},
+		{b.gccCmd(obj), stringList(cflags, "-x", "objective-c"), mfiles},
+	} {
+		if len(run.files) == 0 {
+			continue
+		}
+		args := stringList(run.cmd, run.flags, "-M", "-MG", run.files)
+		cmd := exec.Command(args[0], args[1:]...)
+		cmd.Dir = p.Dir
+		out, err := cmd.Output()
+		if err != nil {
+			return nil, false
+		}
+		for _, file := range strings.Fields(strings.Replace(string(out), "\\\n", " ", -1)) {
+			if strings.HasSuffix(file, ":") {
+				continue // make target
+			}
+			file = filepath.Clean(mkAbs(p.Dir, file))
+			if dir := filepath.Dir(file); dir == filepath.Clean(obj) || dir == p.Dir {
+				continue // generated, or hashed as one of p's files
+			}
+			if _, err := os.Stat(file); err == nil {
+				seen[file] = true
+			}
+		}
+	}
+	var headers []string
+	for file := range seen {
+		headers = append(headers, file)
+	}
+	sort.Strings(headers)
+	return headers, true
+}
+
+// cgoPreamble returns the C preamble of the cgo file f, the comment
+// on its import "C" declaration, without the #cgo lines that only cgo
+// understands.
+func cgoPreamble(f *ast.File) []byte {
+	var buf []byte
+	for _, decl := range f.Decls {
+		d, ok := decl.(*ast.GenDecl)
+		if !ok || d.Tok != token.IMPORT {
+			continue
+		}
+		for _, spec := range d.Specs {
+			s := spec.(*ast.ImportSpec)
+			if path, _ := strconv.Unquote(s.Path.Value); path != "C" {
+				continue
+			}
+			doc := s.Doc
+			if doc == nil && len(d.Specs) == 1 {
+				doc = d.Doc
+			}
+			if doc == nil {
+				continue
+			}
+			for _, line := range strings.Split(doc.Text(), "\n") {
+				if strings.HasPrefix(strings.TrimSpace(line), "#cgo") {
+					line = ""
+				}
+				buf = append(buf, line+"\n"...)
+			}
+		}
+	}
+	return buf
+}
+
+// hashFile writes a line naming the file followed by its content to h.
+// It reports whether the file could be read.
+func hashFile(h hash.Hash, name, file string) bool {
+	f, err := os.Open(file)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	fmt.Fprintf(h, "%s\n", name)
+	_, err = io.Copy(h, f)
+	return err == nil
+}
+
+// cgoCacheGet copies the files recorded in the cache entry for key
+// into obj and returns their names, split into Go files and objects
+// as returned by builder.cgo.  It reports whether the entry was found
+// and copied.
+func (b *builder) cgoCacheGet(key, obj string) (outGo, outObj []string, ok bool) {
+	dir := cgoCacheDir()
+	if dir == "" || key == "" {
+		return nil, nil, false
+	}
+	dir = filepath.Join(dir, key[:2], key)
+	f, err := os.Open(filepath.Join(dir, "manifest"))
+	if err != nil {
+		return nil, nil, false
+	}
+	defer f.Close()
+	// Record the use, for cgoCacheTrim.
+	if fi, err := f.Stat(); err == nil && time.Since(fi.ModTime()) > cgoCacheTouchInterval {
+		now := time.Now()
+		os.Chtimes(filepath.Join(dir, "manifest"), now, now)
+	}
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		kind, name := "", ""
+		if i := strings.Index(s.Text(), " "); i >= 0 {
+			kind, name = s.Text()[:i], s.Text()[i+1:]
+		}
+		if name == "" || name != filepath.Base(name) {
+			return nil, nil, false
+		}
+		if err := b.copyFile(nil, obj+name, filepath.Join(dir, name), 0666); err != nil {
+			return nil, nil, false
+		}
+		switch kind {
+		case "go":
+			outGo = append(outGo, obj+name)
+		case "obj":
+			outObj = append(outObj, obj+name)
+		default:
+			return nil, nil, false
+		}
+	}
+	if s.Err() != nil {
+		return nil, nil, false
+	}
+	return outGo, outObj, true
+}
+
+// cgoCachePut records the files produced by builder.cgo in the cache
+// entry for key.  Failing to update the cache is not an error:
+// the next build will simply run cgo again.
+func (b *builder) cgoCachePut(key, obj string, outGo, outObj []string) {
+	dir := cgoCacheDir()
+	if dir == "" || key == "" || buildN {
+		return
+	}
+	final := filepath.Join(dir, key[:2], key)
+	if _, err := os.Stat(final); err == nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(final), 0777); err != nil {
+		return
+	}
+	// Populate a temporary directory and rename it into place,
+	// so that a concurrent build never sees a partial entry.
+	tmp, err := ioutil.TempDir(filepath.Dir(final), "tmp-")
+	if err != nil {
+		return
+	}
+	defer os.RemoveAll(tmp)
+
+	var manifest []byte
+	add := func(kind string, files []string) bool {
+		for _, file := range files {
+			if !strings.HasPrefix(file, obj) {
+				return false
+			}
+			name := file[len(obj):]
+			data, err := ioutil.ReadFile(file)
+			if err != nil {
+				return false
+			}
+			if err := ioutil.WriteFile(filepath.Join(tmp, name), data, 0666); err != nil {
+				return false
+			}
+			manifest = append(manifest, kind+" "+name+"\n"...)
+		}
+		return true
+	}
+	if !add("go", outGo) || !add("obj", outObj) {
+		return
+	}
+	if err := ioutil.WriteFile(filepath.Join(tmp, "manifest"), manifest, 0666); err != nil {
+		return
+	}
+	os.Rename(tmp, final)
+	cgoCacheTrim(dir)
+}
+
+// cgoCacheTrim removes the entries in the cache directory dir that
+// have not been used for cgoCacheTrimAge, along with temporary
+// directories left behind by interrupted builds.  A file named trim
+// records the last scan, so that the cache is scanned at most once
+// every cgoCacheTrimInterval.
+func cgoCacheTrim(dir string) {
+	stamp := filepath.Join(dir, "trim")
+	if fi, err := os.Stat(stamp); err == nil && time.Since(fi.ModTime()) < cgoCacheTrimInterval {
+		return
+	}
+	if err := ioutil.WriteFile(stamp, nil, 0666); err != nil {
+		return
+	}
+	subdirs, _ := ioutil.ReadDir(dir)
+	for _, sub := range subdirs {
+		if !sub.IsDir() {
+			continue
+		}
+		subdir := filepath.Join(dir, sub.Name())
+		entries, _ := ioutil.ReadDir(subdir)
+		for _, entry := range entries {
+			name := filepath.Join(subdir, entry.Name())
+			used := entry.ModTime()
+			if fi, err := os.Stat(filepath.Join(name, "manifest")); err == nil {
+				used = fi.ModTime()
+			}
+			if time.Since(used) > cgoCacheTrimAge {
+				os.RemoveAll(name)
+			}
+		}
+	}
+}
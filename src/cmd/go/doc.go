@@ -46,7 +46,7 @@ Compile packages and dependencies
 
 Usage:
 
-	go build [-o output] [build flags] [packages]
+	go build [-o output] [-run] [-objdir dir] [build flags] [packages]
 
 Build compiles the packages named by the import paths,
 along with their dependencies, but it does not install the results.
@@ -54,6 +54,10 @@ along with their dependencies, but it does not install the results.
 If the arguments are a list of .go files, build treats them as a list
 of source files specifying a single package.
 
+An argument of - causes build to read further import paths from
+standard input, one per line. This avoids limits on the length of
+the command line when building many packages.
+
 When the command line specifies a single main package,
 build writes the resulting executable to output.
 Otherwise build compiles the packages but discards the results,
@@ -67,12 +71,49 @@ derives from the first file name mentioned, such as f1 for 'go build
 f1.go f2.go'; with no files provided ('go build'), the output file
 name is the base name of the containing directory.
 
+The -run flag runs the executable once it is built. It requires
+the command line to specify a single main package: the first argument
+names the package, or the leading arguments ending in .go list its
+files, and the remaining arguments are passed to the program, as in
+'go build -run ./cmd/tool arg1 arg2'.
+
+The -objdir flag keeps the compiled package archives instead of
+discarding them: each package named on the command line is compiled
+and its archive copied into the directory, at the path it would have
+under a pkg/GOOS_GOARCH directory, such as dir/my/pkg.a. Main
+packages are compiled but not linked. The flag cannot be used with
+-o or -run.
+
 The build flags are shared by the build, install, run, and test commands:
 
 	-a
 		force rebuilding of packages that are already up-to-date.
+	-cgo=false
+		disable cgo for this command, as CGO_ENABLED=0 does:
+		files that import "C" are skipped and files with a +build cgo
+		constraint are excluded, so that files with a !cgo constraint
+		are used in their place. The flag affects only the go command
+		it is given to; it does not change the environment of the
+		commands that go runs.
+	-checkimports
+		before compiling each package, parse its Go files and
+		report imports that a file does not use, failing the build
+		without running the compiler.
+	-debug-buildcontext
+		before building, print the target operating system and
+		architecture, whether cgo is enabled, the build tags, and
+		the compiler that the build uses, for debugging.
+	-generate
+		run the go.generate commands of the packages named on the
+		command line before compiling them (see below).
+	-maxmem n
+		limit linking and running the C compiler so that the
+		memory they are estimated to need stays below n bytes.
+		Other build steps still run with the parallelism set by -p.
 	-n
 		print the commands but do not run them.
+		With -v, also print for each package whether it is
+		up to date and, if not, why it must be rebuilt.
 	-p n
 		the number of builds that can be run in parallel.
 		The default is the number of CPUs available.
@@ -81,6 +122,8 @@ The build flags are shared by the build, install, run, and test commands:
 		Supported only on linux/amd64, darwin/amd64 and windows/amd64.
 	-v
 		print the names of packages as they are compiled.
+		For cgo packages, also print the generated files and
+		objects that correspond to each source file.
 	-work
 		print the name of the temporary work directory and
 		do not delete it when exiting.
@@ -89,12 +132,27 @@ The build flags are shared by the build, install, run, and test commands:
 
 	-ccflags 'arg list'
 		arguments to pass on each 5c, 6c, or 8c compiler invocation.
+	-compiledb file
+		when the command exits, write the C and C++ compiler commands run
+		for cgo packages to file as a compile_commands.json compilation
+		database, for use by clang tools and editors. Packages that
+		are up to date are not compiled, so use -a to record them all.
+		Files generated by cgo are in the work directory, which
+		-work keeps.
 	-compiler name
 		name of compiler to use, as in runtime.Compiler (gccgo or gc).
+	-debug-actiongraph file
+		when the command exits, write the graph of the build actions it
+		ran to file as JSON, for debugging. A file name of - means
+		standard output.
 	-gccgoflags 'arg list'
 		arguments to pass on each gccgo compiler/linker invocation.
 	-gcflags 'arg list'
 		arguments to pass on each 5g, 6g, or 8g compiler invocation.
+		A list of the form 'pattern=arg list' passes the arguments
+		only when compiling packages whose import paths match the
+		pattern, as in -gcflags 'mypkg/...=-N -l'. The flag may be
+		given once for all packages and once for each pattern.
 	-installsuffix suffix
 		a suffix to use in the name of the package installation directory,
 		in order to keep output separate from default builds.
@@ -103,13 +161,23 @@ The build flags are shared by the build, install, run, and test commands:
 	-ldflags 'flag list'
 		arguments to pass on each 5l, 6l, or 8l linker invocation.
 	-tags 'tag list'
-		a list of build tags to consider satisfied during the build.
-		See the documentation for the go/build package for
-		more information about build tags.
+		a list of build tags to consider satisfied during the build,
+		separated by spaces or commas. The flag may be repeated to
+		add more tags. See the documentation for the go/build package
+		for more information about build tags.
 
 The list flags accept a space-separated list of strings. To embed spaces
 in an element in the list, surround it with either single or double quotes.
 
+If the -generate flag is given and the directory of a package named
+on the command line contains a file named go.generate, each non-blank
+line of that file not beginning with # is a command to run, in the
+package directory, before compiling the package. The build then
+includes the source files the commands create. The arguments of each
+command are split like the list flags. Generated files may import only
+packages that the package's other files already import. The commands
+of dependencies are never run.
+
 For more about specifying packages, see 'go help packages'.
 For more about where packages and binaries are installed,
 run 'go help gopath'.  For more about calling between Go and C/C++,
@@ -259,7 +327,8 @@ Usage:
 	go install [build flags] [packages]
 
 Install compiles and installs the packages named by the import paths,
-along with their dependencies.
+along with their dependencies. As with build, an argument of - reads
+import paths from standard input.
 
 For more about the build flags, see 'go help build'.
 For more about specifying packages, see 'go help packages'.
@@ -271,7 +340,7 @@ List packages
 
 Usage:
 
-	go list [-e] [-race] [-f format] [-json] [-tags 'tag list'] [packages]
+	go list [-e] [-race] [-cgo=false] [-f format] [-json] [-tags 'tag list'] [packages]
 
 List lists the packages named by the import paths, one per line.
 
@@ -303,8 +372,10 @@ which calls strings.Join. The struct being passed to the template is:
         IgnoredGoFiles []string // .go sources ignored due to build constraints
         CFiles   []string       // .c source files
         CXXFiles []string       // .cc, .cxx and .cpp source files
+        MFiles   []string       // .m (Objective-C) source files
         HFiles   []string       // .h, .hh, .hpp and .hxx source files
         SFiles   []string       // .s source files
+        CapSFiles []string      // .S source files
         SwigFiles []string      // .swig files
         SwigCXXFiles []string   // .swigcxx files
         SysoFiles []string      // .syso object files to add to archive
@@ -345,7 +416,8 @@ a non-nil Error field; other information may or may not be missing
 (zeroed).
 
 The -tags flag specifies a list of build tags, like in the 'go build'
-command.
+command. Similarly, -cgo=false lists packages as they would be built
+with cgo disabled.
 
 The -race flag causes the package data to include the dependencies
 required by the race detector.
@@ -480,8 +552,21 @@ will be passed to SWIG with the -c++ option.
 
 When either cgo or SWIG is used, go build will pass any .c, .s, or .S
 files to the C compiler, and any .cc, .cpp, .cxx files to the C++
-compiler.  The CC or CXX environment variables may be set to determine
-the C or C++ compiler, respectively, to use.
+compiler.  With cgo on darwin, .m files are also passed to the C
+compiler, as Objective-C.  The CC or CXX environment variables may be
+set to determine the C or C++ compiler, respectively, to use.
+When cross-compiling, the CC_FOR_TARGET and CXX_FOR_TARGET
+environment variables, if set, take precedence over CC and CXX.
+They should name compilers that already generate code for the
+target, so go build does not pass them -m32 or -m64.
+
+The outputs of cgo and of the C compiler are cached in a per-user
+directory and reused when a package and the tools and flags used to
+build it have not changed, including the C headers the package
+includes; use go build -a to ignore the cache.  Entries that have
+not been used for five days are removed.  The GOCGOCACHE environment
+variable sets the cache directory; setting it to off disables the
+cache.
 
 
 GOPATH environment variable
@@ -0,0 +1,133 @@
+// Copyright 2013 The Go Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// A package directory may contain a file named go.generate listing
+// commands that produce some of the package's source files.  When the
+// -generate flag is given and the package is named on the command line,
+// the commands run in the package directory before it is compiled, and
+// the build then picks up the files they created.  Each line of the
+// file is one command, with arguments split as in the -gcflags list;
+// blank lines and lines beginning with # are ignored.
+const generateFile = "go.generate"
+
+// loadGenerate records the go.generate commands of the packages
+// named on the command line, if the -generate flag is set.  The
+// commands of other packages, such as dependencies fetched by go get,
+// never run.
+func loadGenerate(pkgs []*Package) {
+	if !buildGenerate {
+		return
+	}
+	for _, p := range pkgs {
+		if p.Error != nil {
+			continue
+		}
+		cmds, err := readGenerate(p.Dir)
+		if err != nil {
+			p.Error = &PackageError{Err: err.Error()}
+			continue
+		}
+		p.generate = cmds
+	}
+}
+
+// readGenerate returns the commands listed in the go.generate file
+// in dir, or nil if there is no such file or it lists no commands.
+func readGenerate(dir string) ([][]string, error) {
+	f, err := os.Open(filepath.Join(dir, generateFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	var cmds [][]string
+	s := bufio.NewScanner(f)
+	for line := 1; s.Scan(); line++ {
+		text := strings.TrimSpace(s.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		args, err := splitQuotedFields(text)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %v", generateFile, line, err)
+		}
+		cmds = append(cmds, args)
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	return cmds, nil
+}
+
+// generateAction returns the action running the go.generate commands
+// for the package in p's directory.  Builds of different packages in
+// the same directory, such as a package and its test variant, share it.
+func (b *builder) generateAction(p *Package) *action {
+	a := b.genCache[p.Dir]
+	if a == nil {
+		a = &action{p: p, f: (*builder).generate}
+		b.genCache[p.Dir] = a
+	}
+	return a
+}
+
+// generate runs the go.generate commands for a.p.
+func (b *builder) generate(a *action) error {
+	for _, args := range a.p.generate {
+		if err := b.run(a.p.Dir, a.p.ImportPath, nil, args); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addGenerated adds to p the source files created by its go.generate
+// commands, by scanning its directory again.  Only files new to the
+// package are added, so that a test variant keeps its test files.
+// The generated files may import only packages the package already
+// imports, since the dependencies were loaded before they existed.
+func (p *Package) addGenerated() error {
+	bp, err := buildContext.ImportDir(p.Dir, 0)
+	if err != nil {
+		return err
+	}
+	for _, path := range bp.Imports {
+		if path != "C" && !hasString(p.Imports, path) && !hasString(p.build.Imports, path) {
+			return fmt.Errorf("generated files import %q, which the package does not import", path)
+		}
+	}
+	for _, l := range []struct {
+		have *[]string
+		now  []string
+	}{
+		{&p.GoFiles, bp.GoFiles},
+		{&p.CgoFiles, bp.CgoFiles},
+		{&p.CFiles, bp.CFiles},
+		{&p.CXXFiles, bp.CXXFiles},
+		{&p.HFiles, bp.HFiles},
+		{&p.SFiles, bp.SFiles},
+		{&p.CapSFiles, bp.CapSFiles},
+		{&p.SysoFiles, bp.SysoFiles},
+	} {
+		for _, file := range l.now {
+			if !hasString(*l.have, file) {
+				*l.have = append(*l.have, file)
+			}
+		}
+	}
+	return nil
+}
@@ -21,8 +21,21 @@ will be passed to SWIG with the -c++ option.
 
 When either cgo or SWIG is used, go build will pass any .c, .s, or .S
 files to the C compiler, and any .cc, .cpp, .cxx files to the C++
-compiler.  The CC or CXX environment variables may be set to determine
-the C or C++ compiler, respectively, to use.
+compiler.  With cgo on darwin, .m files are also passed to the C
+compiler, as Objective-C.  The CC or CXX environment variables may be
+set to determine the C or C++ compiler, respectively, to use.
+When cross-compiling, the CC_FOR_TARGET and CXX_FOR_TARGET
+environment variables, if set, take precedence over CC and CXX.
+They should name compilers that already generate code for the
+target, so go build does not pass them -m32 or -m64.
+
+The outputs of cgo and of the C compiler are cached in a per-user
+directory and reused when a package and the tools and flags used to
+build it have not changed, including the C headers the package
+includes; use go build -a to ignore the cache.  Entries that have
+not been used for five days are removed.  The GOCGOCACHE environment
+variable sets the cache directory; setting it to off disables the
+cache.
 	`,
 }
 
@@ -0,0 +1,73 @@
+// Copyright 2013 The Go Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// checkImports reports the imports in the Go files of p that the file
+// never refers to, for the -checkimports flag.  It only parses the
+// files, so that unused imports are reported before the compiler runs.
+// A package is taken to be used if the file mentions pkg.Name for a
+// pkg that is not declared locally; imports for side effects, dot
+// imports and imports of "C" are never reported.
+func (b *builder) checkImports(p *Package) error {
+	names := make(map[string]string) // import path -> package name
+	dirs := make(map[string]string)  // directory -> package name
+	for _, p1 := range p.imports {
+		names[p1.ImportPath] = p1.Name
+		dirs[p1.Dir] = p1.Name
+	}
+
+	var msgs []string
+	fset := token.NewFileSet()
+	for _, file := range stringList(p.GoFiles, p.CgoFiles) {
+		f, err := parser.ParseFile(fset, filepath.Join(p.Dir, file), nil, 0)
+		if err != nil {
+			// Leave syntax errors to the compiler.
+			return nil
+		}
+		used := make(map[string]bool)
+		ast.Inspect(f, func(n ast.Node) bool {
+			if sel, ok := n.(*ast.SelectorExpr); ok {
+				if x, ok := sel.X.(*ast.Ident); ok && x.Obj == nil {
+					used[x.Name] = true
+				}
+			}
+			return true
+		})
+		for _, spec := range f.Imports {
+			path, err := strconv.Unquote(spec.Path.Value)
+			if err != nil || path == "C" {
+				continue
+			}
+			var name string
+			if spec.Name != nil {
+				name = spec.Name.Name
+			} else if build.IsLocalImport(path) {
+				name = dirs[filepath.Join(p.Dir, path)]
+			} else {
+				name = names[path]
+			}
+			if name == "" || name == "_" || name == "." || used[name] {
+				continue
+			}
+			msgs = append(msgs, fmt.Sprintf("%s: imported and not used: %q", fset.Position(spec.Pos()), path))
+		}
+	}
+	if len(msgs) > 0 {
+		b.showOutput(p.Dir, p.ImportPath, strings.Join(msgs, "\n")+"\n")
+		return errPrintedOutput
+	}
+	return nil
+}
@@ -14,7 +14,7 @@ import (
 )
 
 var cmdList = &Command{
-	UsageLine: "list [-e] [-race] [-f format] [-json] [-tags 'tag list'] [packages]",
+	UsageLine: "list [-e] [-race] [-cgo=false] [-f format] [-json] [-tags 'tag list'] [packages]",
 	Short:     "list packages",
 	Long: `
 List lists the packages named by the import paths, one per line.
@@ -47,8 +47,10 @@ which calls strings.Join. The struct being passed to the template is:
         IgnoredGoFiles []string // .go sources ignored due to build constraints
         CFiles   []string       // .c source files
         CXXFiles []string       // .cc, .cxx and .cpp source files
+        MFiles   []string       // .m (Objective-C) source files
         HFiles   []string       // .h, .hh, .hpp and .hxx source files
         SFiles   []string       // .s source files
+        CapSFiles []string      // .S source files
         SwigFiles []string      // .swig files
         SwigCXXFiles []string   // .swigcxx files
         SysoFiles []string      // .syso object files to add to archive
@@ -89,7 +91,8 @@ a non-nil Error field; other information may or may not be missing
 (zeroed).
 
 The -tags flag specifies a list of build tags, like in the 'go build'
-command.
+command. Similarly, -cgo=false lists packages as they would be built
+with cgo disabled.
 
 The -race flag causes the package data to include the dependencies
 required by the race detector.
@@ -101,7 +104,8 @@ For more about specifying packages, see 'go help packages'.
 func init() {
 	cmdList.Run = runList // break init cycle
 	cmdList.Flag.Var(buildCompiler{}, "compiler", "")
-	cmdList.Flag.Var((*stringsFlag)(&buildContext.BuildTags), "tags", "")
+	cmdList.Flag.Var((*tagsFlag)(&buildContext.BuildTags), "tags", "")
+	cmdList.Flag.BoolVar(&buildContext.CgoEnabled, "cgo", buildContext.CgoEnabled, "")
 }
 
 var listE = cmdList.Flag.Bool("e", false, "")
@@ -5,6 +5,7 @@
 package main
 
 import (
+	"bufio"
 	"bytes"
 	"flag"
 	"fmt"
@@ -310,6 +311,32 @@ func importPathsNoDotExpansion(args []string) []string {
 	return out
 }
 
+// stdinImportPaths returns args with an argument "-" replaced by
+// the import paths read from standard input, one per line.
+// A path that appears more than once on standard input is kept
+// only the first time; the other arguments are left as they are.
+func stdinImportPaths(args []string) []string {
+	var out []string
+	for _, a := range args {
+		if a != "-" {
+			out = append(out, a)
+			continue
+		}
+		seen := make(map[string]bool)
+		s := bufio.NewScanner(os.Stdin)
+		for s.Scan() {
+			if line := strings.TrimSpace(s.Text()); line != "" && !seen[line] {
+				seen[line] = true
+				out = append(out, line)
+			}
+		}
+		if err := s.Err(); err != nil {
+			fatalf("reading import paths from standard input: %v", err)
+		}
+	}
+	return out
+}
+
 // importPaths returns the import paths to use for the given command line.
 func importPaths(args []string) []string {
 	args = importPathsNoDotExpansion(args)
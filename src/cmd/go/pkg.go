@@ -42,8 +42,10 @@ type Package struct {
 	IgnoredGoFiles []string `json:",omitempty"` // .go sources ignored due to build constraints
 	CFiles         []string `json:",omitempty"` // .c source files
 	CXXFiles       []string `json:",omitempty"` // .cc, .cpp and .cxx source files
+	MFiles         []string `json:",omitempty"` // .m (Objective-C) source files
 	HFiles         []string `json:",omitempty"` // .h, .hh, .hpp and .hxx source files
 	SFiles         []string `json:",omitempty"` // .s source files
+	CapSFiles      []string `json:",omitempty"` // .S source files
 	SwigFiles      []string `json:",omitempty"` // .swig files
 	SwigCXXFiles   []string `json:",omitempty"` // .swigcxx files
 	SysoFiles      []string `json:",omitempty"` // .syso system object files added to package
@@ -79,6 +81,7 @@ type Package struct {
 	sfiles       []string
 	allgofiles   []string             // gofiles + IgnoredGoFiles, absolute paths
 	target       string               // installed file for this package (may be executable)
+	staleReason  string               // why Stale is set, for -n -v
 	fake         bool                 // synthesized package
 	forceBuild   bool                 // this package must be rebuilt
 	forceLibrary bool                 // this package is a library (even if named "main")
@@ -88,6 +91,7 @@ type Package struct {
 	exeName      string               // desired name for temporary executable
 	coverMode    string               // preprocess Go source files with the coverage tool in this mode
 	coverVars    map[string]*CoverVar // variables created by coverage analysis
+	generate     [][]string           // commands from go.generate, run before building
 }
 
 // CoverVar holds the name of the generated coverage variables targeting the named file.
@@ -113,8 +117,10 @@ func (p *Package) copyBuild(pp *build.Package) {
 	p.IgnoredGoFiles = pp.IgnoredGoFiles
 	p.CFiles = pp.CFiles
 	p.CXXFiles = pp.CXXFiles
+	p.MFiles = pp.MFiles
 	p.HFiles = pp.HFiles
 	p.SFiles = pp.SFiles
+	p.CapSFiles = pp.CapSFiles
 	p.SwigFiles = pp.SwigFiles
 	p.SwigCXXFiles = pp.SwigCXXFiles
 	p.SysoFiles = pp.SysoFiles
@@ -431,7 +437,7 @@ func (p *Package) load(stk *importStack, bp *build.Package, err error) *Package
 	}
 	sort.Strings(p.gofiles)
 
-	p.sfiles = stringList(p.SFiles)
+	p.sfiles = stringList(p.SFiles, p.CapSFiles)
 	for i := range p.sfiles {
 		p.sfiles[i] = filepath.Join(p.Dir, p.sfiles[i])
 	}
@@ -454,8 +460,10 @@ func (p *Package) load(stk *importStack, bp *build.Package, err error) *Package
 		p.IgnoredGoFiles,
 		p.CFiles,
 		p.CXXFiles,
+		p.MFiles,
 		p.HFiles,
 		p.SFiles,
+		p.CapSFiles,
 		p.SysoFiles,
 		p.SwigFiles,
 		p.SwigCXXFiles,
@@ -600,18 +608,19 @@ func computeStale(pkgs ...*Package) {
 	}
 
 	for _, p := range packageList(pkgs) {
-		p.Stale = isStale(p, topRoot)
+		p.Stale, p.staleReason = isStale(p, topRoot)
 	}
 }
 
-// isStale reports whether package p needs to be rebuilt.
-func isStale(p *Package, topRoot map[string]bool) bool {
+// isStale reports whether package p needs to be rebuilt,
+// and if so, why.
+func isStale(p *Package, topRoot map[string]bool) (bool, string) {
 	if p.Standard && (p.ImportPath == "unsafe" || buildContext.Compiler == "gccgo") {
 		// fake, builtin package
-		return false
+		return false, ""
 	}
 	if p.Error != nil {
-		return true
+		return true, "package has errors"
 	}
 
 	// A package without Go sources means we only found
@@ -621,11 +630,20 @@ func isStale(p *Package, topRoot map[string]bool) bool {
 	// only useful with the specific version of the toolchain that
 	// created them.
 	if len(p.gofiles) == 0 && !p.usesSwig() {
-		return false
+		return false, ""
 	}
 
-	if buildA || p.target == "" || p.Stale {
-		return true
+	if buildA {
+		return true, "-a flag set"
+	}
+	if p.target == "" {
+		return true, "no install target"
+	}
+	if p.Stale {
+		if p.staleReason != "" {
+			return true, p.staleReason
+		}
+		return true, "marked stale"
 	}
 
 	// Package is stale if completely unbuilt.
@@ -634,7 +652,7 @@ func isStale(p *Package, topRoot map[string]bool) bool {
 		built = fi.ModTime()
 	}
 	if built.IsZero() {
-		return true
+		return true, "target " + p.target + " missing"
 	}
 
 	olderThan := func(file string) bool {
@@ -644,8 +662,11 @@ func isStale(p *Package, topRoot map[string]bool) bool {
 
 	// Package is stale if a dependency is, or if a dependency is newer.
 	for _, p1 := range p.deps {
-		if p1.Stale || p1.target != "" && olderThan(p1.target) {
-			return true
+		if p1.Stale {
+			return true, "dependency " + p1.ImportPath + " is stale"
+		}
+		if p1.target != "" && olderThan(p1.target) {
+			return true, "dependency " + p1.ImportPath + " is newer"
 		}
 	}
 
@@ -660,10 +681,10 @@ func isStale(p *Package, topRoot map[string]bool) bool {
 	// See issue 4106.
 	if p.Root != goroot {
 		if olderThan(buildToolchain.compiler()) {
-			return true
+			return true, "compiler is newer"
 		}
 		if p.build.IsCommand() && olderThan(buildToolchain.linker()) {
-			return true
+			return true, "linker is newer"
 		}
 	}
 
@@ -678,17 +699,20 @@ func isStale(p *Package, topRoot map[string]bool) bool {
 	// listed in $GOPATH a separate compilation world.
 	// See issue 3149.
 	if p.Root != "" && !topRoot[p.Root] {
-		return false
+		return false, ""
 	}
 
-	srcs := stringList(p.GoFiles, p.CFiles, p.CXXFiles, p.HFiles, p.SFiles, p.CgoFiles, p.SysoFiles, p.SwigFiles, p.SwigCXXFiles)
+	srcs := stringList(p.GoFiles, p.CFiles, p.CXXFiles, p.MFiles, p.HFiles, p.SFiles, p.CapSFiles, p.CgoFiles, p.SysoFiles, p.SwigFiles, p.SwigCXXFiles)
+	if p.generate != nil {
+		srcs = append(srcs, generateFile)
+	}
 	for _, src := range srcs {
 		if olderThan(filepath.Join(p.Dir, src)) {
-			return true
+			return true, "source file " + src + " is newer"
 		}
 	}
 
-	return false
+	return false, ""
 }
 
 var cwd, _ = os.Getwd()
@@ -806,6 +830,7 @@ func packagesAndErrors(args []string) []*Package {
 			set[arg] = true
 		}
 	}
+	loadGenerate(pkgs)
 	computeStale(pkgs...)
 
 	return pkgs
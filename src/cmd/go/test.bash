@@ -326,6 +326,114 @@ if [ $(./testgo test fmt fmt fmt fmt fmt | wc -l) -ne 1 ] ; then
     ok=false
 fi
 
+TEST go build reads import paths from standard input
+if ! printf 'fmt\nstrings\n\nfmt\n' | ./testgo build - errors; then
+	echo 'go build - did not build the packages listed on standard input'
+	ok=false
+fi
+if printf 'no-such-package\n' | ./testgo build - 2>/dev/null; then
+	echo 'go build - did not fail for a missing package read from standard input'
+	ok=false
+fi
+
+TEST go build -debug-actiongraph
+d=$(mktemp -d -t testgoXXX)
+if ! ./testgo build -debug-actiongraph=$d/graph.json errors; then
+	echo 'go build -debug-actiongraph failed'
+	ok=false
+elif ! grep -q '"Package": "errors"' $d/graph.json; then
+	echo 'action graph does not mention package errors:'
+	cat $d/graph.json
+	ok=false
+fi
+# go test -i -c runs two builds: installing the dependencies,
+# then building the test binary. The graph has the actions of both.
+if ! ./testgo test -i -c -debug-actiongraph=$d/graph.json errors; then
+	echo 'go test -i -c -debug-actiongraph failed'
+	ok=false
+elif ! grep -A1 '"Mode": "install"' $d/graph.json | grep -q '"Package": "testing"' || ! grep -q '"Package": "testmain"' $d/graph.json; then
+	echo 'action graph does not have the actions of both builds:'
+	cat $d/graph.json
+	ok=false
+fi
+rm -f errors.test
+rm -rf $d
+
+TEST go build -maxmem
+d=$(mktemp -d -t testgoXXX)
+if ! ./testgo build -maxmem=1 -o $d/gofmt cmd/gofmt; then
+	echo 'go build -maxmem=1 failed'
+	ok=false
+fi
+if ! ./testgo test -maxmem=1 errors; then
+	echo 'go test -maxmem=1 failed'
+	ok=false
+fi
+rm -rf $d
+
+TEST -gcflags pattern=flags applies only to matching packages
+./testgo build -n -a -gcflags -m -gcflags 'sync/...=-N -l' sync/atomic >testgo.log 2>&1 || ok=false
+if ! grep -q 'g -o .*sync/atomic/_obj/_go_.* -m -N -l -p sync/atomic' testgo.log; then
+	echo 'go build -gcflags sync/...=-N -l did not pass -N -l when compiling sync/atomic'
+	cat testgo.log
+	ok=false
+fi
+if ! grep -q 'g -o .*/runtime/_obj/_go_.* -m -p runtime' testgo.log; then
+	echo 'go build -gcflags sync/...=-N -l did not pass only -m when compiling runtime'
+	cat testgo.log
+	ok=false
+fi
+rm -f testgo.log
+
+TEST go install -n -v explains staleness
+d=$(TMPDIR=/var/tmp mktemp -d -t testgoXXX)
+mkdir -p $d/src/example/stale
+cat >$d/src/example/stale/main.go <<EOF
+package main
+func main() {}
+EOF
+export GOPATH=$d
+if ! ./testgo install -n -v example/stale 2>&1 | grep -q '^# example/stale: stale: target .* missing$'; then
+	echo 'go install -n -v did not report missing target'
+	ok=false
+fi
+./testgo install example/stale || ok=false
+if ! ./testgo install -n -v example/stale 2>&1 | grep -q '^# example/stale: up to date$'; then
+	echo 'go install -n -v did not report up-to-date package'
+	ok=false
+fi
+if ! ./testgo build -n -v example/stale 2>&1 | grep -q '^# example/stale: up to date$'; then
+	echo 'go build -n -v did not report up-to-date package'
+	ok=false
+fi
+if ! ./testgo install -a -n -v example/stale 2>&1 | grep -q '^# example/stale: stale: -a flag set$'; then
+	echo 'go install -a -n -v did not report -a'
+	ok=false
+fi
+unset GOPATH
+rm -rf $d
+
+TEST go build -o into a missing directory fails early
+d=$(mktemp -d -t testgoXXX)
+if ./testgo build -o $d/missing/gofmt cmd/gofmt 2>$d/err; then
+	echo 'go build -o into missing directory succeeded'
+	ok=false
+elif ! grep -q "directory $d/missing does not exist" $d/err; then
+	echo 'go build -o into missing directory: unexpected error:'
+	cat $d/err
+	ok=false
+fi
+touch $d/file
+if ./testgo build -o $d/file/gofmt cmd/gofmt 2>$d/err; then
+	echo 'go build -o into a file succeeded'
+	ok=false
+elif ! grep -q "$d/file is not a directory" $d/err; then
+	echo 'go build -o into a file: unexpected error:'
+	cat $d/err
+	ok=false
+fi
+rm -rf $d
+
 # ensure that output of 'go list' is consistent between runs
 TEST go list is consistent
 ./testgo list std > test_std.list
@@ -474,6 +582,231 @@ fi
 unset GOPATH
 rm -rf $d
 
+TEST -tags splits on spaces and commas and may be repeated
+d=$(TMPDIR=/var/tmp mktemp -d -t testgoXXX)
+mkdir -p $d/src/example/tags
+for tag in a b c; do
+	cat >$d/src/example/tags/$tag.go <<EOF
+// +build $tag
+
+package tags
+EOF
+done
+export GOPATH=$d
+for tags in "-tags=a,b,c" "-tags=a -tags=b,c" "-tags='a b' -tags=c,a"; do
+	if [ "$(eval ./testgo list $tags -f '{{.GoFiles}}' example/tags)" != "[a.go b.go c.go]" ]; then
+		echo "go list $tags did not select all three files"
+		ok=false
+	fi
+done
+unset GOPATH
+rm -rf $d
+
+TEST -cgo=false selects the !cgo files
+d=$(TMPDIR=/var/tmp mktemp -d -t testgoXXX)
+mkdir -p $d/src/example/nocgo
+echo 'package nocgo' >$d/src/example/nocgo/a.go
+echo '// +build cgo
+
+package nocgo' >$d/src/example/nocgo/withcgo.go
+echo '// +build !cgo
+
+package nocgo' >$d/src/example/nocgo/withoutcgo.go
+echo 'package nocgo
+import "C"' >$d/src/example/nocgo/c.go
+export GOPATH=$d
+if [ "$(CGO_ENABLED=1 ./testgo list -cgo=false -f '{{.GoFiles}} {{.CgoFiles}}' example/nocgo)" != "[a.go withoutcgo.go] []" ]; then
+	echo "go list -cgo=false did not select the !cgo files"
+	CGO_ENABLED=1 ./testgo list -cgo=false -f '{{.GoFiles}} {{.CgoFiles}}' example/nocgo
+	ok=false
+fi
+if [ "$(CGO_ENABLED=1 ./testgo list -f '{{.GoFiles}} {{.CgoFiles}}' example/nocgo)" != "[a.go withcgo.go] [c.go]" ]; then
+	echo "go list without -cgo=false did not select the cgo files"
+	ok=false
+fi
+unset GOPATH
+rm -rf $d
+
+TEST go.generate runs before the build with -generate
+d=$(TMPDIR=/var/tmp mktemp -d -t testgoXXX)
+mkdir -p $d/src/gen $d/src/dep $d/src/empty
+echo 'package main
+
+import _ "dep"
+
+func main() { println(generated) }' >$d/src/gen/main.go
+echo 'echo "package main; const generated = 42" >gen.go' >$d/src/gen/gen.sh
+echo '# make the generated file
+sh gen.sh' >$d/src/gen/go.generate
+echo 'package dep' >$d/src/dep/dep.go
+echo 'touch ran' >$d/src/dep/go.generate
+echo 'package empty' >$d/src/empty/empty.go
+echo '# nothing to do' >$d/src/empty/go.generate
+export GOPATH=$d
+if ./testgo build -o $d/gen.exe gen 2>/dev/null; then
+	echo "go build without -generate ran the go.generate command"
+	ok=false
+elif [ -f $d/src/gen/gen.go ]; then
+	echo "go build without -generate ran the go.generate command"
+	ok=false
+elif ! ./testgo build -generate -n gen 2>&1 | grep -q 'sh gen.sh'; then
+	echo "go build -generate -n did not show the go.generate command"
+	ok=false
+elif [ -f $d/src/gen/gen.go ]; then
+	echo "go build -generate -n ran the go.generate command"
+	ok=false
+elif ! ./testgo build -generate -o $d/gen.exe gen; then
+	echo "go build -generate failed"
+	ok=false
+elif [ "$($d/gen.exe 2>&1)" != "42" ]; then
+	echo "generated file was not compiled"
+	ok=false
+elif [ -f $d/src/dep/ran ]; then
+	echo "go build -generate ran the go.generate command of a dependency"
+	ok=false
+elif ! ./testgo build -generate empty; then
+	echo "go build -generate failed for a go.generate file listing no commands"
+	ok=false
+fi
+echo 'echo "package main; import _ \"net\"" >gen2.go' >$d/src/gen/gen2.sh
+echo 'sh gen2.sh' >>$d/src/gen/go.generate
+if ./testgo build -generate -o $d/gen.exe gen 2>$d/err; then
+	echo "go build succeeded with a generated file importing a new package"
+	ok=false
+elif ! grep -q 'generated files import "net"' $d/err; then
+	echo "wrong error for generated file importing a new package"
+	cat $d/err
+	ok=false
+fi
+unset GOPATH
+rm -rf $d
+
+TEST go install rejects two commands with the same target
+d=$(TMPDIR=/var/tmp mktemp -d -t testgoXXX)
+mkdir -p $d/src/a/tool $d/src/b/tool
+echo 'package main
+func main() { println("a") }' >$d/src/a/tool/main.go
+echo 'package main
+func main() { println("b") }' >$d/src/b/tool/main.go
+export GOPATH=$d
+if ./testgo install a/tool b/tool 2>$d/err; then
+	echo "go install of two commands named tool succeeded"
+	ok=false
+elif ! grep -q 'a/tool and b/tool both install to' $d/err; then
+	echo "go install did not report the shared target"
+	cat $d/err
+	ok=false
+elif [ -f $d/bin/tool ]; then
+	echo "go install wrote bin/tool anyway"
+	ok=false
+fi
+unset GOPATH
+rm -rf $d
+
+TEST go build -run runs a single main package
+d=$(TMPDIR=/var/tmp mktemp -d -t testgoXXX)
+mkdir -p $d/src/hello $d/src/lib
+echo 'package main
+import ("fmt"; "os")
+func main() { fmt.Println(os.Args[1:]) }' >$d/src/hello/main.go
+echo 'package lib' >$d/src/lib/lib.go
+export GOPATH=$d
+testgo=$(pwd)/testgo
+if [ "$(cd $d && $testgo build -run hello x y)" != "[x y]" ]; then
+	echo "go build -run did not run the program with its arguments"
+	ok=false
+elif [ ! -f $d/hello ]; then
+	echo "go build -run did not write the executable"
+	ok=false
+elif ./testgo build -run lib 2>$d/err; then
+	echo "go build -run of a non-main package succeeded"
+	ok=false
+elif ! grep -q 'requires a single main package' $d/err; then
+	echo "go build -run did not explain the failure"
+	cat $d/err
+	ok=false
+fi
+unset GOPATH
+rm -rf $d
+
+TEST go build -debug-buildcontext prints the build context
+if ! CGO_ENABLED=0 ./testgo build -n -debug-buildcontext -tags 'x y' errors 2>testdata/err; then
+	echo "go build -debug-buildcontext failed"
+	ok=false
+elif ! grep -q '^CGO_ENABLED=0$' testdata/err || ! grep -q '^tags=x y$' testdata/err || ! grep -q '^compiler=gc ' testdata/err; then
+	echo "go build -debug-buildcontext printed the wrong build context"
+	cat testdata/err
+	ok=false
+fi
+rm -f testdata/err
+
+TEST go build -checkimports reports unused imports
+d=$(TMPDIR=/var/tmp mktemp -d -t testgoXXX)
+mkdir -p $d/src/p/sub
+echo 'package sub
+func F() {}' >$d/src/p/sub/sub.go
+echo 'package p
+import (
+	"fmt"
+	"os"
+	str "strings"
+	_ "unicode"
+	"p/sub"
+)
+var _ = fmt.Sprint(str.ToUpper(""))
+func f() { os := 1; _ = os }' >$d/src/p/p.go
+export GOPATH=$d
+if ./testgo build -checkimports p 2>$d/err; then
+	echo "go build -checkimports succeeded with unused imports"
+	ok=false
+elif ! grep -q 'p.go:4:.*imported and not used: "os"' $d/err || ! grep -q 'imported and not used: "p/sub"' $d/err; then
+	echo "go build -checkimports did not report the unused imports"
+	cat $d/err
+	ok=false
+elif grep -q '"fmt"\|"strings"\|"unicode"' $d/err; then
+	echo "go build -checkimports reported used imports"
+	cat $d/err
+	ok=false
+fi
+sed -i.bak 's/func f() {.*/func f() { sub.F(); os.Exit(0) }/' $d/src/p/p.go
+if ! ./testgo build -checkimports p; then
+	echo "go build -checkimports failed with all imports used"
+	ok=false
+fi
+unset GOPATH
+rm -rf $d
+
+TEST go build -objdir keeps the package archives
+d=$(TMPDIR=/var/tmp mktemp -d -t testgoXXX)
+mkdir -p $d/src/hello $d/src/my/lib
+echo 'package main
+import "my/lib"
+func main() { lib.F() }' >$d/src/hello/main.go
+echo 'package lib
+func F() {}' >$d/src/my/lib/lib.go
+export GOPATH=$d
+if ! ./testgo build -objdir $d/obj hello my/lib; then
+	echo "go build -objdir failed"
+	ok=false
+elif [ ! -f $d/obj/hello.a -o ! -f $d/obj/my/lib.a ]; then
+	echo "go build -objdir did not write the package archives"
+	find $d/obj
+	ok=false
+elif [ -e hello -o -d $d/pkg -o -d $d/bin ]; then
+	echo "go build -objdir linked or installed packages"
+	ok=false
+elif ./testgo build -objdir $d/obj -o $d/hello hello 2>$d/err; then
+	echo "go build -objdir -o succeeded"
+	ok=false
+elif ! grep -q 'cannot use -objdir with -o' $d/err; then
+	echo "go build -objdir -o did not explain the failure"
+	cat $d/err
+	ok=false
+fi
+unset GOPATH
+rm -f hello
+rm -rf $d
+
 TEST case collisions '(issue 4773)'
 d=$(TMPDIR=/var/tmp mktemp -d -t testgoXXX)
 export GOPATH=$d
@@ -613,6 +946,131 @@ fi
 rm -rf $d
 unset GOPATH
 
+TEST cgo reuses cached output
+d=$(TMPDIR=/var/tmp mktemp -d -t testgoXXX)
+export GOPATH=$d
+export GOCGOCACHE=$d/cache
+mkdir -p $d/src/cached
+echo '
+package cached
+// int f(void) { return 1; }
+import "C"
+
+func F() int { return int(C.f()) }
+' >$d/src/cached/cached.go
+if ! ./testgo build cached; then
+	echo first build failed
+	ok=false
+elif ! ./testgo build -x cached >$d/out 2>&1; then
+	echo second build failed
+	cat $d/out
+	ok=false
+elif grep -q 'cgo.* -objdir' $d/out; then
+	echo second build ran cgo again
+	cat $d/out
+	ok=false
+fi
+echo '// changed' >>$d/src/cached/cached.go
+if ! ./testgo build -x cached >$d/out 2>&1; then
+	echo build after change failed
+	cat $d/out
+	ok=false
+elif ! grep -q 'cgo.* -objdir' $d/out; then
+	echo build after change did not run cgo
+	ok=false
+fi
+mkdir -p $d/src/ext $d/include
+echo 'int g(void);' >$d/include/ext.h
+echo '
+package ext
+// #cgo CFLAGS: -I'$d'/include
+// #include "ext.h"
+// int g(void) { return 2; }
+import "C"
+
+func G() int { return int(C.g()) }
+' >$d/src/ext/ext.go
+./testgo build ext || ok=false
+if ./testgo build -x ext 2>&1 | grep -q 'cgo.* -objdir'; then
+	echo second build of package with external header ran cgo again
+	ok=false
+fi
+echo '#define EXT 1' >>$d/include/ext.h
+if ! ./testgo build -x ext >$d/out 2>&1; then
+	echo build after header change failed
+	cat $d/out
+	ok=false
+elif ! grep -q 'cgo.* -objdir' $d/out; then
+	echo build after change to an external header did not run cgo
+	ok=false
+fi
+# Entries unused for days are removed by the next store.
+entries=$(ls -d $d/cache/??/*)
+touch -d '10 days ago' $d/cache/trim $(for e in $entries; do echo $e $e/manifest; done)
+echo '// changed again' >>$d/src/cached/cached.go
+./testgo build cached || ok=false
+for e in $entries; do
+	if [ -d $e ]; then
+		echo unused cache entry $e was not removed
+		ok=false
+	fi
+done
+if [ $(ls -d $d/cache/??/* | wc -l) != 1 ]; then
+	echo new cache entry was not kept
+	ls -d $d/cache/??/*
+	ok=false
+fi
+rm -rf $d
+unset GOPATH GOCGOCACHE
+
+TEST cgo -compiledb records C compilations
+d=$(TMPDIR=/var/tmp mktemp -d -t testgoXXX)
+export GOPATH=$d
+mkdir -p $d/src/ccdb
+echo '
+package ccdb
+// int g(void);
+import "C"
+
+func F() int { return int(C.g()) }
+' >$d/src/ccdb/ccdb.go
+echo 'int g(void) { return 2; }' >$d/src/ccdb/g.c
+if ! ./testgo build -compiledb $d/compile_commands.json ccdb; then
+	echo build failed
+	ok=false
+elif ! grep -q '"file": ".*/src/ccdb/g.c"' $d/compile_commands.json; then
+	echo compilation database does not list g.c
+	cat $d/compile_commands.json
+	ok=false
+elif ! grep -q '"directory": ".*/src/ccdb"' $d/compile_commands.json; then
+	echo compilation database has wrong directory
+	cat $d/compile_commands.json
+	ok=false
+fi
+# go test -i -c installs ccdb in one build and compiles ccdb2 in another.
+mkdir -p $d/src/ccdb2
+echo '
+package ccdb2
+// int h(void);
+import "C"
+import "ccdb"
+
+func F() int { return int(C.h()) + ccdb.F() }
+' >$d/src/ccdb2/ccdb2.go
+echo 'int h(void) { return 3; }' >$d/src/ccdb2/h.c
+echo 'package ccdb2' >$d/src/ccdb2/ccdb2_test.go
+if ! ./testgo test -i -c -compiledb $d/compile_commands.json ccdb2; then
+	echo go test -i -c failed
+	ok=false
+elif ! grep -q '"file": ".*/src/ccdb/g.c"' $d/compile_commands.json || ! grep -q '"file": ".*/src/ccdb2/h.c"' $d/compile_commands.json; then
+	echo compilation database does not list the files of both builds
+	cat $d/compile_commands.json
+	ok=false
+fi
+rm -f ccdb2.test
+rm -rf $d
+unset GOPATH
+
 TEST 'Issue 6480: "go test -c -test.bench=XXX fmt" should not hang'
 if ! ./testgo test -c -test.bench=XXX fmt; then
 	echo build test failed
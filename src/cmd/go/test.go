@@ -409,7 +409,8 @@ func runTest(cmd *Command, args []string) {
 		// Mark all the coverage packages for rebuilding with coverage.
 		for _, p := range testCoverPkgs {
 			p.Stale = true // rebuild
-			p.fake = true  // do not warn about rebuild
+			p.staleReason = "built with coverage"
+			p.fake = true // do not warn about rebuild
 			p.coverMode = testCoverMode
 			p.coverVars = declareCoverVars(p.ImportPath, p.GoFiles...)
 		}
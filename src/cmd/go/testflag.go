@@ -74,6 +74,7 @@ var testFlagDefn = []*testFlagSpec{
 	{name: "a", boolVar: &buildA},
 	{name: "n", boolVar: &buildN},
 	{name: "p"},
+	{name: "maxmem"},
 	{name: "x", boolVar: &buildX},
 	{name: "work", boolVar: &buildWork},
 	{name: "gcflags"},
@@ -82,7 +83,13 @@ var testFlagDefn = []*testFlagSpec{
 	{name: "tags"},
 	{name: "compiler"},
 	{name: "race", boolVar: &buildRace},
+	{name: "cgo", boolVar: &buildContext.CgoEnabled},
+	{name: "checkimports", boolVar: &buildCheckImports},
+	{name: "generate", boolVar: &buildGenerate},
 	{name: "installsuffix"},
+	{name: "debug-actiongraph"},
+	{name: "debug-buildcontext", boolVar: &buildDebugBuildContext},
+	{name: "compiledb"},
 
 	// passed to 6.out, adding a "test." prefix to the name if necessary: -v becomes -test.v.
 	{name: "bench", passToTest: true},
@@ -150,12 +157,14 @@ func testFlags(args []string) (packageNames, passToTest []string) {
 		var err error
 		switch f.name {
 		// bool flags.
-		case "a", "c", "i", "n", "x", "v", "race", "cover", "work":
+		case "a", "c", "i", "n", "x", "v", "race", "cgo", "cover", "work", "debug-buildcontext", "checkimports", "generate":
 			setBoolFlag(f.boolVar, value)
 		case "p":
 			setIntFlag(&buildP, value)
+		case "maxmem":
+			setInt64Flag(&buildMaxmem, value)
 		case "gcflags":
-			buildGcflags, err = splitQuotedFields(value)
+			err = gcflagsFlag{}.Set(value)
 			if err != nil {
 				fatalf("invalid flag argument for -%s: %v", f.name, err)
 			}
@@ -170,9 +179,13 @@ func testFlags(args []string) (packageNames, passToTest []string) {
 				fatalf("invalid flag argument for -%s: %v", f.name, err)
 			}
 		case "tags":
-			buildContext.BuildTags = strings.Fields(value)
+			(*tagsFlag)(&buildContext.BuildTags).Set(value)
 		case "compiler":
 			buildCompiler{}.Set(value)
+		case "debug-actiongraph":
+			buildDebugActiongraph = value
+		case "compiledb":
+			buildCompileDB = value
 		case "file":
 			testFiles = append(testFiles, value)
 		case "bench":
@@ -292,6 +305,14 @@ func setIntFlag(flag *int, value string) {
 	*flag = x
 }
 
+func setInt64Flag(flag *int64, value string) {
+	x, err := strconv.ParseInt(value, 0, 64)
+	if err != nil {
+		testSyntaxError("illegal int flag value " + value)
+	}
+	*flag = x
+}
+
 func testSyntaxError(msg string) {
 	fmt.Fprintf(os.Stderr, "go test: %s\n", msg)
 	fmt.Fprintf(os.Stderr, `run "go help test" or "go help testflag" for more information`+"\n")
@@ -33,6 +33,7 @@ type Context struct {
 	GOPATH      string // Go path
 	CgoEnabled  bool   // whether cgo can be used
 	UseAllFiles bool   // use files regardless of +build lines, file names
+	LooseScan   bool   // accept files with any package clause; see Package.FilePackages
 	Compiler    string // compiler to assume when computing target paths
 
 	// The build and release tags specify build constraints
@@ -72,7 +73,9 @@ type Context struct {
 	IsAbsPath func(path string) bool
 
 	// IsDir reports whether the path names a directory.
-	// If IsDir is nil, Import calls os.Stat and uses the result's IsDir method.
+	// If IsDir is nil, Import calls os.Stat and uses the result's IsDir method,
+	// and it uses the IsDir method of the entries returned by ReadDir
+	// to skip subdirectories when scanning a package directory.
 	IsDir func(path string) bool
 
 	// HasSubdir reports whether dir is a subdirectory of
@@ -358,12 +361,34 @@ type Package struct {
 	IgnoredGoFiles []string // .go source files ignored for this build
 	CFiles         []string // .c source files
 	CXXFiles       []string // .cc, .cpp and .cxx source files
+	MFiles         []string // .m (Objective-C) source files
 	HFiles         []string // .h, .hh, .hpp and .hxx source files
-	SFiles         []string // .s source files
+	SFiles         []string // .s source files; never .S files, even with cgo
+	CapSFiles      []string // .S source files, which need the C preprocessor
 	SwigFiles      []string // .swig files
 	SwigCXXFiles   []string // .swigcxx files
 	SysoFiles      []string // .syso system object files to add to archive
 
+	// FilePackages maps the name of each .go file to the package name
+	// in its package clause. It is set only when Context.LooseScan is
+	// true, in which case files whose package names differ from Name,
+	// including package documentation files, are kept instead of
+	// being ignored. Name is then taken from the first file that is
+	// neither package documentation nor an external test, and a
+	// mismatch is reported by a *MultiplePackageError alongside the
+	// complete Package.
+	FilePackages map[string]string
+
+	// IgnoredReasons maps each file in IgnoredGoFiles to a short
+	// explanation of why it was excluded, such as "+build !linux".
+	IgnoredReasons map[string]string
+
+	// FileConstraints maps the name of each source file whose name
+	// restricts it to an operating system or architecture, such as
+	// x_linux_amd64.go, to that restriction: "linux/amd64", "linux",
+	// or "amd64". Files in IgnoredGoFiles are included.
+	FileConstraints map[string]string
+
 	// Cgo directives
 	CgoCFLAGS    []string // Cgo CFLAGS directives
 	CgoCPPFLAGS  []string // Cgo CPPFLAGS directives
@@ -397,6 +422,164 @@ func (ctxt *Context) ImportDir(dir string, mode ImportMode) (*Package, error) {
 	return ctxt.Import(".", dir, mode)
 }
 
+// IsCommandDir reports whether the Go package in the named directory
+// is a command, that is, whether it is named "main". Unlike ImportDir,
+// it stops at the first Go file that belongs to the package and reads
+// only the file's package clause and imports. Test files are not
+// consulted, and files are skipped for the same reasons as in Import:
+// build constraints, package documentation, and imports of "C" when
+// cgo is disabled. If no Go file belongs to the package, IsCommandDir
+// returns a *NoGoError.
+func (ctxt *Context) IsCommandDir(dir string) (bool, error) {
+	dirs, err := ctxt.readDir(dir)
+	if err != nil {
+		return false, err
+	}
+	fset := token.NewFileSet()
+	for _, d := range dirs {
+		name := d.Name()
+		if ctxt.IsDir != nil {
+			if ctxt.IsDir(ctxt.joinPath(dir, name)) {
+				continue
+			}
+		} else if d.IsDir() {
+			continue
+		}
+		if nameExt(name) != ".go" || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+
+		match, _, data, filename, err := ctxt.matchFile(dir, name, true, nil)
+		if err != nil {
+			return false, err
+		}
+		if !match {
+			continue
+		}
+		pf, err := parser.ParseFile(fset, filename, data, parser.ImportsOnly)
+		if err != nil {
+			return false, err
+		}
+		pkg := pf.Name.Name
+		if pkg == "documentation" && !ctxt.LooseScan {
+			continue
+		}
+		if !ctxt.CgoEnabled && importsC(pf) {
+			continue
+		}
+		return pkg == "main", nil
+	}
+	return false, &NoGoError{dir}
+}
+
+// HashSources writes the names and contents of the source files of p,
+// as found by Import, to w, which is typically a hash.Hash. A tool can
+// then compare the resulting sums to decide whether a package is out of
+// date by its content rather than by file modification times. The
+// output depends only on the file lists and contents, so it is the same
+// for the same sources. Test files are not included. The files are read
+// through ctxt.OpenFile, so a virtual file system is honored.
+func (ctxt *Context) HashSources(p *Package, w io.Writer) error {
+	for _, l := range []struct {
+		kind  string
+		files []string
+	}{
+		{"go", p.GoFiles},
+		{"cgo", p.CgoFiles},
+		{"c", p.CFiles},
+		{"cxx", p.CXXFiles},
+		{"m", p.MFiles},
+		{"h", p.HFiles},
+		{"s", p.SFiles},
+		{"S", p.CapSFiles},
+		{"swig", p.SwigFiles},
+		{"swigcxx", p.SwigCXXFiles},
+		{"syso", p.SysoFiles},
+	} {
+		for _, name := range l.files {
+			f, err := ctxt.openFile(ctxt.joinPath(p.Dir, name))
+			if err != nil {
+				return err
+			}
+			data, err := ioutil.ReadAll(f)
+			f.Close()
+			if err != nil {
+				return err
+			}
+			// The length makes the boundary between files unambiguous.
+			if _, err := fmt.Fprintf(w, "%s %q %d\n", l.kind, name, len(data)); err != nil {
+				return err
+			}
+			if _, err := w.Write(data); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// importsC reports whether f imports the pseudo-package "C".
+func importsC(f *ast.File) bool {
+	for _, spec := range f.Imports {
+		if path, err := strconv.Unquote(spec.Path.Value); err == nil && path == "C" {
+			return true
+		}
+	}
+	return false
+}
+
+// Deps returns the import paths of all the packages that the package
+// named by path imports, directly or indirectly, sorted and without
+// duplicates. The package itself and the pseudo-package "C" are not
+// included. Local import paths are interpreted relative to srcDir,
+// and each dependency's own imports relative to its directory;
+// a local import that does not correspond to a standard import path
+// is reported by its directory. An import cycle does not cause an
+// error: each package is visited only once.
+//
+// If a package cannot be imported, Deps returns the error along
+// with the paths collected so far.
+func (ctxt *Context) Deps(path, srcDir string) ([]string, error) {
+	p, err := ctxt.Import(path, srcDir, 0)
+	if err != nil {
+		return nil, err
+	}
+	seen := map[string]bool{depKey(p): true}
+	var deps []string
+	queue := []*Package{p}
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+		for _, imp := range p.Imports {
+			if imp == "C" || !IsLocalImport(imp) && seen[imp] {
+				continue
+			}
+			dep, err := ctxt.Import(imp, p.Dir, 0)
+			if err != nil {
+				sort.Strings(deps)
+				return deps, fmt.Errorf("import %q (imported by %s): %v", imp, depKey(p), err)
+			}
+			key := depKey(dep)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			deps = append(deps, key)
+			queue = append(queue, dep)
+		}
+	}
+	sort.Strings(deps)
+	return deps, nil
+}
+
+// depKey returns the name under which Deps reports p.
+func depKey(p *Package) string {
+	if IsLocalImport(p.ImportPath) {
+		return p.Dir
+	}
+	return p.ImportPath
+}
+
 // NoGoError is the error used by Import to describe a directory
 // containing no buildable Go source files. (It may still contain
 // test files, files hidden by build tags, and so on.)
@@ -408,6 +591,20 @@ func (e *NoGoError) Error() string {
 	return "no buildable Go source files in " + e.Dir
 }
 
+// MultiplePackageError is the error used by Import to describe a
+// directory containing files from two different packages. When
+// Context.LooseScan is set, Import still returns the package with
+// all of its files, along with the error for the first mismatch.
+type MultiplePackageError struct {
+	Dir      string   // directory containing the files
+	Packages []string // package names found
+	Files    []string // corresponding files: Files[i] declares package Packages[i]
+}
+
+func (e *MultiplePackageError) Error() string {
+	return fmt.Sprintf("found packages %s (%s) and %s (%s) in %s", e.Packages[0], e.Files[0], e.Packages[1], e.Files[1], e.Dir)
+}
+
 func nameExt(name string) string {
 	i := strings.LastIndex(name, ".")
 	if i < 0 {
@@ -422,10 +619,10 @@ func nameExt(name string) string {
 // using a standard import path, the returned package will set p.ImportPath
 // to that path.
 //
-// In the directory containing the package, .go, .c, .h, and .s files are
+// In the directory containing the package, .go, .c, .h, .m, and .s files are
 // considered part of the package except for:
 //
-//	- .go files in package documentation
+//	- .go files in package documentation, unless ctxt.LooseScan is set
 //	- files starting with _ or . (likely editor temporary files)
 //	- files with build constraints not satisfied by the context
 //
@@ -588,7 +785,6 @@ Found:
 		return p, err
 	}
 
-	var Sfiles []string // files with ".S" (capital S)
 	var firstFile string
 	imported := make(map[string][]token.Position)
 	testImported := make(map[string][]token.Position)
@@ -596,20 +792,32 @@ Found:
 	allTags := make(map[string]bool)
 	fset := token.NewFileSet()
 	for _, d := range dirs {
-		if d.IsDir() {
+		name := d.Name()
+		if ctxt.IsDir != nil {
+			// Trust the IsDir hook over the FileInfo
+			// returned by a virtual ReadDir.
+			if ctxt.IsDir(ctxt.joinPath(p.Dir, name)) {
+				continue
+			}
+		} else if d.IsDir() {
 			continue
 		}
 
-		name := d.Name()
 		ext := nameExt(name)
 
-		match, data, filename, err := ctxt.matchFile(p.Dir, name, true, allTags)
+		match, reason, data, filename, err := ctxt.matchFile(p.Dir, name, true, allTags)
 		if err != nil {
 			return p, err
 		}
+		if c := fileNameConstraint(name); c != "" && (match || ext == ".go") {
+			if p.FileConstraints == nil {
+				p.FileConstraints = make(map[string]string)
+			}
+			p.FileConstraints[name] = c
+		}
 		if !match {
 			if ext == ".go" {
-				p.IgnoredGoFiles = append(p.IgnoredGoFiles, name)
+				p.ignore(name, reason)
 			}
 			continue
 		}
@@ -622,6 +830,9 @@ Found:
 		case ".cc", ".cpp", ".cxx":
 			p.CXXFiles = append(p.CXXFiles, name)
 			continue
+		case ".m":
+			p.MFiles = append(p.MFiles, name)
+			continue
 		case ".h", ".hh", ".hpp", ".hxx":
 			p.HFiles = append(p.HFiles, name)
 			continue
@@ -629,7 +840,7 @@ Found:
 			p.SFiles = append(p.SFiles, name)
 			continue
 		case ".S":
-			Sfiles = append(Sfiles, name)
+			p.CapSFiles = append(p.CapSFiles, name)
 			continue
 		case ".swig":
 			p.SwigFiles = append(p.SwigFiles, name)
@@ -651,8 +862,13 @@ Found:
 		}
 
 		pkg := pf.Name.Name
-		if pkg == "documentation" {
-			p.IgnoredGoFiles = append(p.IgnoredGoFiles, name)
+		if ctxt.LooseScan {
+			if p.FilePackages == nil {
+				p.FilePackages = make(map[string]string)
+			}
+			p.FilePackages[name] = pkg
+		} else if pkg == "documentation" {
+			p.ignore(name, "package documentation")
 			continue
 		}
 
@@ -663,11 +879,24 @@ Found:
 			pkg = pkg[:len(pkg)-len("_test")]
 		}
 
-		if p.Name == "" {
+		switch {
+		case ctxt.LooseScan && (pkg == "documentation" || isXTest):
+			// Neither names the package in the directory.
+		case p.Name == "":
 			p.Name = pkg
 			firstFile = name
-		} else if pkg != p.Name {
-			return p, fmt.Errorf("found packages %s (%s) and %s (%s) in %s", p.Name, firstFile, pkg, name, p.Dir)
+		case pkg != p.Name:
+			err := &MultiplePackageError{
+				Dir:      p.Dir,
+				Packages: []string{p.Name, pkg},
+				Files:    []string{firstFile, name},
+			}
+			if !ctxt.LooseScan {
+				return p, err
+			}
+			if pkgerr == nil {
+				pkgerr = err
+			}
 		}
 		if pf.Doc != nil && p.Doc == "" {
 			p.Doc = doc.Synopsis(pf.Doc.Text())
@@ -719,7 +948,7 @@ Found:
 			if ctxt.CgoEnabled {
 				p.CgoFiles = append(p.CgoFiles, name)
 			} else {
-				p.IgnoredGoFiles = append(p.IgnoredGoFiles, name)
+				p.ignore(name, `imports "C" but cgo is disabled`)
 			}
 		} else if isXTest {
 			p.XTestGoFiles = append(p.XTestGoFiles, name)
@@ -742,14 +971,6 @@ Found:
 	p.TestImports, p.TestImportPos = cleanImports(testImported)
 	p.XTestImports, p.XTestImportPos = cleanImports(xTestImported)
 
-	// add the .S files only if we are using cgo
-	// (which means gcc will compile them).
-	// The standard assemblers expect .s files.
-	if len(p.CgoFiles) > 0 {
-		p.SFiles = append(p.SFiles, Sfiles...)
-		sort.Strings(p.SFiles)
-	}
-
 	return p, pkgerr
 }
 
@@ -760,10 +981,19 @@ Found:
 // MatchFile considers the name of the file and may use ctxt.OpenFile to
 // read some or all of the file's content.
 func (ctxt *Context) MatchFile(dir, name string) (match bool, err error) {
-	match, _, _, err = ctxt.matchFile(dir, name, false, nil)
+	match, _, _, _, err = ctxt.matchFile(dir, name, false, nil)
 	return
 }
 
+// ignore records that the Go file name was ignored, and why.
+func (p *Package) ignore(name, reason string) {
+	p.IgnoredGoFiles = append(p.IgnoredGoFiles, name)
+	if p.IgnoredReasons == nil {
+		p.IgnoredReasons = make(map[string]string)
+	}
+	p.IgnoredReasons[name] = reason
+}
+
 // matchFile determines whether the file with the given name in the given directory
 // should be included in the package being constructed.
 // It returns the data read from the file.
@@ -772,9 +1002,11 @@ func (ctxt *Context) MatchFile(dir, name string) (match bool, err error) {
 // considers text until the first non-comment.
 // If allTags is non-nil, matchFile records any encountered build tag
 // by setting allTags[tag] = true.
-func (ctxt *Context) matchFile(dir, name string, returnImports bool, allTags map[string]bool) (match bool, data []byte, filename string, err error) {
+// If the file does not match, reason says why.
+func (ctxt *Context) matchFile(dir, name string, returnImports bool, allTags map[string]bool) (match bool, reason string, data []byte, filename string, err error) {
 	if strings.HasPrefix(name, "_") ||
 		strings.HasPrefix(name, ".") {
+		reason = "file name begins with _ or ."
 		return
 	}
 
@@ -785,11 +1017,12 @@ func (ctxt *Context) matchFile(dir, name string, returnImports bool, allTags map
 	ext := name[i:]
 
 	if !ctxt.goodOSArchFile(name, allTags) && !ctxt.UseAllFiles {
+		reason = "file name suffix does not match " + ctxt.GOOS + "/" + ctxt.GOARCH
 		return
 	}
 
 	switch ext {
-	case ".go", ".c", ".cc", ".cxx", ".cpp", ".s", ".h", ".hh", ".hpp", ".hxx", ".S", ".swig", ".swigcxx":
+	case ".go", ".c", ".cc", ".cxx", ".cpp", ".m", ".s", ".h", ".hh", ".hpp", ".hxx", ".S", ".swig", ".swigcxx":
 		// tentatively okay - read to make sure
 	case ".syso":
 		// binary, no reading
@@ -818,7 +1051,8 @@ func (ctxt *Context) matchFile(dir, name string, returnImports bool, allTags map
 	}
 
 	// Look for +build comments to accept or reject the file.
-	if !ctxt.shouldBuild(data, allTags) && !ctxt.UseAllFiles {
+	if ok, why := ctxt.shouldBuildReason(data, allTags); !ok && !ctxt.UseAllFiles {
+		reason = why
 		return
 	}
 
@@ -845,6 +1079,11 @@ func ImportDir(dir string, mode ImportMode) (*Package, error) {
 	return Default.ImportDir(dir, mode)
 }
 
+// Deps is shorthand for Default.Deps.
+func Deps(path, srcDir string) ([]string, error) {
+	return Default.Deps(path, srcDir)
+}
+
 var slashslash = []byte("//")
 
 // shouldBuild reports whether it is okay to use this file,
@@ -861,6 +1100,13 @@ var slashslash = []byte("//")
 // marks the file as applicable only on Windows and Linux.
 //
 func (ctxt *Context) shouldBuild(content []byte, allTags map[string]bool) bool {
+	ok, _ := ctxt.shouldBuildReason(content, allTags)
+	return ok
+}
+
+// shouldBuildReason is like shouldBuild but, if the file is rejected,
+// also returns the first unsatisfied build line, such as "+build !linux".
+func (ctxt *Context) shouldBuildReason(content []byte, allTags map[string]bool) (ok bool, reason string) {
 	// Pass 1. Identify leading run of // comments and blank lines,
 	// which must be followed by a blank line.
 	end := 0
@@ -885,7 +1131,6 @@ func (ctxt *Context) shouldBuild(content []byte, allTags map[string]bool) bool {
 
 	// Pass 2.  Process each line in the run.
 	p = content
-	allok := true
 	for len(p) > 0 {
 		line := p
 		if i := bytes.IndexByte(line, '\n'); i >= 0 {
@@ -899,22 +1144,82 @@ func (ctxt *Context) shouldBuild(content []byte, allTags map[string]bool) bool {
 			if len(line) > 0 && line[0] == '+' {
 				// Looks like a comment +line.
 				f := strings.Fields(string(line))
-				if f[0] == "+build" {
-					ok := false
-					for _, tok := range f[1:] {
-						if ctxt.match(tok, allTags) {
-							ok = true
-						}
-					}
-					if !ok {
-						allok = false
-					}
+				if f[0] == "+build" && !ctxt.matchAny(f[1:], allTags) && reason == "" {
+					reason = strings.Join(f, " ")
 				}
 			}
 		}
 	}
 
-	return allok
+	return reason == "", reason
+}
+
+// matchAny reports whether any of the options of a +build line
+// is satisfied by the context. It calls match on every option,
+// so that allTags records all the tags mentioned.
+func (ctxt *Context) matchAny(options []string, allTags map[string]bool) bool {
+	ok := false
+	for _, tok := range options {
+		if ctxt.match(tok, allTags) {
+			ok = true
+		}
+	}
+	return ok
+}
+
+// A Constraint is a build constraint expression, in the form used on
+// a // +build line: a space-separated list of options, any one of
+// which must hold, each a comma-separated list of terms, all of which
+// must hold. A term is a tag, optionally negated by a leading !.
+// For example, "linux,386 darwin,!cgo" holds on Linux/386 and on
+// Darwin without cgo.
+type Constraint struct {
+	options []string
+}
+
+// ParseConstraint parses the build constraint expression expr,
+// which has the syntax of the text following "+build" on a
+// build constraint line.
+func ParseConstraint(expr string) (*Constraint, error) {
+	options := strings.Fields(expr)
+	if len(options) == 0 {
+		return nil, errors.New("empty build constraint")
+	}
+	for _, opt := range options {
+		for _, term := range strings.Split(opt, ",") {
+			tag := strings.TrimPrefix(term, "!")
+			if !isBuildTag(tag) {
+				return nil, fmt.Errorf("invalid build constraint term %q in %q", term, opt)
+			}
+		}
+	}
+	return &Constraint{options}, nil
+}
+
+// isBuildTag reports whether tag is a valid build tag:
+// a non-empty string of letters, digits, underscores and dots.
+func isBuildTag(tag string) bool {
+	if tag == "" {
+		return false
+	}
+	for _, c := range tag {
+		if !unicode.IsLetter(c) && !unicode.IsDigit(c) && c != '_' && c != '.' {
+			return false
+		}
+	}
+	return true
+}
+
+// Match reports whether the constraint is satisfied by ctxt,
+// using the same rules as for the +build lines in source files.
+func (c *Constraint) Match(ctxt *Context) bool {
+	return ctxt.matchAny(c.options, nil)
+}
+
+// String returns the constraint expression,
+// with options separated by single spaces.
+func (c *Constraint) String() string {
+	return strings.Join(c.options, " ")
 }
 
 // saveCgo saves the information from the #cgo lines in the import "C" comment.
@@ -1108,10 +1413,8 @@ func (ctxt *Context) match(name string, allTags map[string]bool) bool {
 
 	// Tags must be letters, digits, underscores or dots.
 	// Unlike in Go identifiers, all digits are fine (e.g., "386").
-	for _, c := range name {
-		if !unicode.IsLetter(c) && !unicode.IsDigit(c) && c != '_' && c != '.' {
-			return false
-		}
+	if !isBuildTag(name) {
+		return false
 	}
 
 	// special tags
@@ -1149,6 +1452,22 @@ func (ctxt *Context) match(name string, allTags map[string]bool) bool {
 //     name_$(GOOS)_$(GOARCH)_test.*
 //
 func (ctxt *Context) goodOSArchFile(name string, allTags map[string]bool) bool {
+	goos, goarch := osArchSuffix(name)
+	if allTags != nil {
+		if goos != "" {
+			allTags[goos] = true
+		}
+		if goarch != "" {
+			allTags[goarch] = true
+		}
+	}
+	return (goos == "" || goos == ctxt.GOOS) && (goarch == "" || goarch == ctxt.GOARCH)
+}
+
+// osArchSuffix returns the $GOOS and $GOARCH named by the suffix of
+// the file name, in the formats accepted by goodOSArchFile.
+// Either or both may be empty.
+func osArchSuffix(name string) (goos, goarch string) {
 	if dot := strings.Index(name, "."); dot != -1 {
 		name = name[:dot]
 	}
@@ -1158,25 +1477,26 @@ func (ctxt *Context) goodOSArchFile(name string, allTags map[string]bool) bool {
 	}
 	n := len(l)
 	if n >= 2 && knownOS[l[n-2]] && knownArch[l[n-1]] {
-		if allTags != nil {
-			allTags[l[n-2]] = true
-			allTags[l[n-1]] = true
-		}
-		return l[n-2] == ctxt.GOOS && l[n-1] == ctxt.GOARCH
+		return l[n-2], l[n-1]
 	}
 	if n >= 1 && knownOS[l[n-1]] {
-		if allTags != nil {
-			allTags[l[n-1]] = true
-		}
-		return l[n-1] == ctxt.GOOS
+		return l[n-1], ""
 	}
 	if n >= 1 && knownArch[l[n-1]] {
-		if allTags != nil {
-			allTags[l[n-1]] = true
-		}
-		return l[n-1] == ctxt.GOARCH
+		return "", l[n-1]
 	}
-	return true
+	return "", ""
+}
+
+// fileNameConstraint returns the restriction that the suffix of the
+// file name places on the operating system and architecture, in the
+// form used by Package.FileConstraints, or "" if there is none.
+func fileNameConstraint(name string) string {
+	goos, goarch := osArchSuffix(name)
+	if goos != "" && goarch != "" {
+		return goos + "/" + goarch
+	}
+	return goos + goarch
 }
 
 var knownOS = make(map[string]bool)
@@ -1191,6 +1511,30 @@ func init() {
 	}
 }
 
+// KnownOSList returns the sorted list of operating systems known to
+// the build package: the values GOOS may take and that file name
+// suffixes such as _linux.go are matched against.
+func KnownOSList() []string {
+	list := strings.Fields(goosList)
+	sort.Strings(list)
+	return list
+}
+
+// KnownArchList returns the sorted list of architectures known to
+// the build package: the values GOARCH may take and that file name
+// suffixes such as _amd64.go are matched against.
+func KnownArchList() []string {
+	list := strings.Fields(goarchList)
+	sort.Strings(list)
+	return list
+}
+
+// CgoSupported reports whether cgo is supported, and therefore
+// enabled by default, for the given operating system and architecture.
+func CgoSupported(goos, goarch string) bool {
+	return cgoEnabled[goos+"/"+goarch]
+}
+
 // ToolDir is the directory containing build tools.
 var ToolDir = filepath.Join(runtime.GOROOT(), "pkg/tool/"+runtime.GOOS+"_"+runtime.GOARCH)
 
@@ -5,13 +5,17 @@
 package build
 
 import (
+	"bytes"
+	"go/token"
 	"io"
 	"os"
 	"path/filepath"
 	"reflect"
 	"runtime"
+	"sort"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestMatch(t *testing.T) {
@@ -145,6 +149,40 @@ func TestShouldBuild(t *testing.T) {
 	}
 }
 
+func TestParseConstraint(t *testing.T) {
+	ctxt := &Context{GOOS: "linux", GOARCH: "amd64", CgoEnabled: true, BuildTags: []string{"tag1"}}
+	for _, tt := range []struct {
+		expr  string
+		match bool
+	}{
+		{"linux", true},
+		{"darwin", false},
+		{"linux,amd64 darwin", true},
+		{"linux,386 darwin", false},
+		{"darwin !cgo", false},
+		{"darwin,!cgo tag1", true},
+		{"!windows,!plan9", true},
+		{"  linux  ", true},
+	} {
+		c, err := ParseConstraint(tt.expr)
+		if err != nil {
+			t.Errorf("ParseConstraint(%q): %v", tt.expr, err)
+			continue
+		}
+		if got := c.Match(ctxt); got != tt.match {
+			t.Errorf("ParseConstraint(%q).Match = %v, want %v", tt.expr, got, tt.match)
+		}
+		if s := c.String(); s != strings.Join(strings.Fields(tt.expr), " ") {
+			t.Errorf("ParseConstraint(%q).String() = %q", tt.expr, s)
+		}
+	}
+	for _, expr := range []string{"", "  ", "!!linux", "linux,", "a-b", "!", "linux darwin,,386"} {
+		if _, err := ParseConstraint(expr); err == nil {
+			t.Errorf("ParseConstraint(%q) succeeded, want error", expr)
+		}
+	}
+}
+
 type readNopCloser struct {
 	io.Reader
 }
@@ -184,3 +222,419 @@ func TestMatchFile(t *testing.T) {
 		}
 	}
 }
+
+// fileInfo is an os.FileInfo for a file in a virtual directory.
+type fileInfo struct {
+	name string
+	dir  bool
+}
+
+func (fi fileInfo) Name() string       { return fi.name }
+func (fi fileInfo) Size() int64        { return 0 }
+func (fi fileInfo) Mode() os.FileMode  { return 0 }
+func (fi fileInfo) ModTime() time.Time { return time.Time{} }
+func (fi fileInfo) IsDir() bool        { return fi.dir }
+func (fi fileInfo) Sys() interface{}   { return nil }
+
+// fakeContext returns a Context for linux/amd64 and the gc compiler
+// whose only directory is /virtual, holding the given files, which
+// map file names to contents. Changes to files are seen by later
+// calls that read the directory.
+func fakeContext(files map[string]string) *Context {
+	ctxt := &Context{GOARCH: "amd64", GOOS: "linux", Compiler: "gc"}
+	ctxt.ReadDir = func(dir string) ([]os.FileInfo, error) {
+		var names []string
+		for name := range files {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		var fi []os.FileInfo
+		for _, name := range names {
+			fi = append(fi, fileInfo{name: name})
+		}
+		return fi, nil
+	}
+	ctxt.OpenFile = func(path string) (io.ReadCloser, error) {
+		data, ok := files[filepath.Base(path)]
+		if !ok || filepath.Dir(path) != "/virtual" {
+			return nil, os.ErrNotExist
+		}
+		return &readNopCloser{strings.NewReader(data)}, nil
+	}
+	ctxt.IsDir = func(path string) bool {
+		return path == "/virtual"
+	}
+	return ctxt
+}
+
+// A limitReader yields data followed by an endless run of
+// declarations, recording how much was read.
+type limitReader struct {
+	data string
+	n    int
+}
+
+func (r *limitReader) Read(b []byte) (int, error) {
+	for i := range b {
+		if r.n < len(r.data) {
+			b[i] = r.data[r.n]
+		} else {
+			b[i] = "var x = 1\n"[(r.n-len(r.data))%10]
+		}
+		r.n++
+	}
+	return len(b), nil
+}
+
+func (r *limitReader) Close() error {
+	return nil
+}
+
+func TestImportOpenFileStreams(t *testing.T) {
+	// The file never ends, so Import must stop reading
+	// once it has seen the imports.
+	r := &limitReader{data: "// +build !nobuild\n\npackage p\n\nimport \"fmt\"\n\n"}
+	ctxt := fakeContext(map[string]string{"big.go": ""})
+	ctxt.OpenFile = func(path string) (io.ReadCloser, error) {
+		if path != filepath.Join("/virtual", "big.go") {
+			t.Fatalf("OpenFile asked for %q", path)
+		}
+		return r, nil
+	}
+	p, err := ctxt.ImportDir("/virtual", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(p.GoFiles, []string{"big.go"}) || !reflect.DeepEqual(p.Imports, []string{"fmt"}) {
+		t.Errorf("GoFiles = %v, Imports = %v, want [big.go], [fmt]", p.GoFiles, p.Imports)
+	}
+	if r.n > 1<<16 {
+		t.Errorf("Import read %d bytes of the file", r.n)
+	}
+}
+
+func TestImportIsDirHook(t *testing.T) {
+	// The virtual ReadDir does not know which entries are
+	// directories; Import must ask IsDir instead.
+	ctxt := fakeContext(map[string]string{"a.go": "package p\n", "sub.go": ""})
+	open := ctxt.OpenFile
+	ctxt.OpenFile = func(path string) (io.ReadCloser, error) {
+		if path != filepath.Join("/virtual", "a.go") {
+			t.Fatalf("OpenFile asked for %q", path)
+		}
+		return open(path)
+	}
+	ctxt.IsDir = func(path string) bool {
+		return path == "/virtual" || path == filepath.Join("/virtual", "sub.go")
+	}
+	p, err := ctxt.ImportDir("/virtual", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(p.GoFiles, []string{"a.go"}) {
+		t.Errorf("GoFiles = %v, want [a.go]", p.GoFiles)
+	}
+}
+
+func TestImportCapitalSFiles(t *testing.T) {
+	// .S files need the C preprocessor, so they are kept apart
+	// from the .s files, whatever the compiler.
+	ctxt := fakeContext(map[string]string{"a.go": "package p\n", "b.s": "", "c.S": ""})
+	for _, compiler := range []string{"gc", "gccgo"} {
+		ctxt.Compiler = compiler
+		p, err := ctxt.ImportDir("/virtual", 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(p.SFiles, []string{"b.s"}) || !reflect.DeepEqual(p.CapSFiles, []string{"c.S"}) {
+			t.Errorf("compiler %s: SFiles = %v, CapSFiles = %v, want [b.s], [c.S]", compiler, p.SFiles, p.CapSFiles)
+		}
+	}
+}
+
+func TestImportMFiles(t *testing.T) {
+	ctxt := fakeContext(map[string]string{"a.go": "package p\n", "a.m": "", "b.c": ""})
+	ctxt.GOOS = "darwin"
+	p, err := ctxt.ImportDir("/virtual", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(p.MFiles, []string{"a.m"}) || !reflect.DeepEqual(p.CFiles, []string{"b.c"}) {
+		t.Errorf("MFiles = %v, CFiles = %v, want [a.m], [b.c]", p.MFiles, p.CFiles)
+	}
+}
+
+func TestIgnoredReasons(t *testing.T) {
+	files := map[string]string{
+		"a.go":         "package p\n",
+		"b_windows.go": "package p\n",
+		"c.go":         "// +build linux\n// +build !amd64 386\n\npackage p\n",
+		"_d.go":        "package p\n",
+		"doc.go":       "package documentation\n",
+	}
+	ctxt := fakeContext(files)
+	p, err := ctxt.ImportDir("/virtual", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"_d.go":        "file name begins with _ or .",
+		"b_windows.go": "file name suffix does not match linux/amd64",
+		"c.go":         "+build !amd64 386",
+		"doc.go":       "package documentation",
+	}
+	if !reflect.DeepEqual(p.IgnoredReasons, want) {
+		t.Errorf("IgnoredReasons = %v, want %v", p.IgnoredReasons, want)
+	}
+	if len(p.IgnoredGoFiles) != len(want) {
+		t.Errorf("IgnoredGoFiles = %v, want %d files", p.IgnoredGoFiles, len(want))
+	}
+}
+
+func TestImportTestImports(t *testing.T) {
+	files := map[string]string{
+		"a.go":      "package p\n\nimport \"fmt\"\n",
+		"a_test.go": "package p\n\nimport \"testing\"\n",
+		"b_test.go": "package p_test\n\nimport (\n\t\"os\"\n\t\"p\"\n\t\"testing\"\n)\n",
+	}
+	ctxt := fakeContext(files)
+	p, err := ctxt.ImportDir("/virtual", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		name    string
+		imports []string
+		pos     map[string][]token.Position
+		file    string
+		want    []string
+	}{
+		{"Imports", p.Imports, p.ImportPos, "a.go", []string{"fmt"}},
+		{"TestImports", p.TestImports, p.TestImportPos, "a_test.go", []string{"testing"}},
+		{"XTestImports", p.XTestImports, p.XTestImportPos, "b_test.go", []string{"os", "p", "testing"}},
+	} {
+		if !reflect.DeepEqual(tt.imports, tt.want) {
+			t.Errorf("%s = %v, want %v", tt.name, tt.imports, tt.want)
+		}
+		if len(tt.pos) != len(tt.want) {
+			t.Errorf("%s has %d positions, want %d", tt.name, len(tt.pos), len(tt.want))
+		}
+		for path, pos := range tt.pos {
+			if len(pos) != 1 || filepath.Base(pos[0].Filename) != tt.file {
+				t.Errorf("%s position of %q = %v, want one in %s", tt.name, path, pos, tt.file)
+			}
+		}
+	}
+}
+
+func TestLooseScan(t *testing.T) {
+	// The external test and the documentation file come first,
+	// but neither names the package.
+	files := map[string]string{
+		"a_test.go": "package p_test\n",
+		"doc.go":    "package documentation\n",
+		"p.go":      "package p\n",
+		"x.go":      "package x\n",
+	}
+	ctxt := fakeContext(files)
+
+	_, err := ctxt.ImportDir("/virtual", 0)
+	if _, ok := err.(*MultiplePackageError); !ok {
+		t.Errorf("ImportDir without LooseScan: err = %v, want *MultiplePackageError", err)
+	}
+
+	ctxt.LooseScan = true
+	p, err := ctxt.ImportDir("/virtual", 0)
+	want := &MultiplePackageError{
+		Dir:      "/virtual",
+		Packages: []string{"p", "x"},
+		Files:    []string{"p.go", "x.go"},
+	}
+	if !reflect.DeepEqual(err, want) {
+		t.Errorf("ImportDir with LooseScan: err = %#v, want %#v", err, want)
+	}
+	if p.Name != "p" {
+		t.Errorf("Name = %q, want %q", p.Name, "p")
+	}
+	if want := []string{"doc.go", "p.go", "x.go"}; !reflect.DeepEqual(p.GoFiles, want) {
+		t.Errorf("GoFiles = %v, want %v", p.GoFiles, want)
+	}
+	if want := []string{"a_test.go"}; !reflect.DeepEqual(p.XTestGoFiles, want) {
+		t.Errorf("XTestGoFiles = %v, want %v", p.XTestGoFiles, want)
+	}
+	wantPkgs := map[string]string{"a_test.go": "p_test", "doc.go": "documentation", "p.go": "p", "x.go": "x"}
+	if !reflect.DeepEqual(p.FilePackages, wantPkgs) {
+		t.Errorf("FilePackages = %v, want %v", p.FilePackages, wantPkgs)
+	}
+
+	// Files of a single package, with documentation, are not an error.
+	files["x.go"] = "package p\n"
+	if _, err := ctxt.ImportDir("/virtual", 0); err != nil {
+		t.Errorf("ImportDir with LooseScan of one package: %v", err)
+	}
+}
+
+func TestKnownLists(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		list func() []string
+		want string
+	}{
+		{"KnownOSList", KnownOSList, runtime.GOOS},
+		{"KnownArchList", KnownArchList, runtime.GOARCH},
+	} {
+		list := tt.list()
+		if !sort.StringsAreSorted(list) {
+			t.Errorf("%s() = %v, not sorted", tt.name, list)
+		}
+		found := false
+		for _, s := range list {
+			found = found || s == tt.want
+		}
+		if !found {
+			t.Errorf("%s() = %v, missing %s", tt.name, list, tt.want)
+		}
+		list[0] = "changed"
+		if tt.list()[0] == "changed" {
+			t.Errorf("%s returned its internal list", tt.name)
+		}
+	}
+	if !CgoSupported("linux", "amd64") {
+		t.Errorf("CgoSupported(linux, amd64) = false, want true")
+	}
+	if CgoSupported("plan9", "386") {
+		t.Errorf("CgoSupported(plan9, 386) = true, want false")
+	}
+}
+
+func TestIsCommandDir(t *testing.T) {
+	files := map[string]string{
+		"a_test.go": "package lib_test\n",
+		"b.go":      "// +build ignore\n\npackage lib\n",
+		"c.go":      "package lib\n\nimport \"C\"\n",
+		"doc.go":    "package documentation\n",
+		"main.go":   "package main\n\nimport \"fmt\"\n",
+	}
+	ctxt := fakeContext(files)
+	if cmd, err := ctxt.IsCommandDir("/virtual"); err != nil || !cmd {
+		t.Errorf("IsCommandDir with cgo disabled = %v, %v, want true, nil", cmd, err)
+	}
+	ctxt.CgoEnabled = true
+	if cmd, err := ctxt.IsCommandDir("/virtual"); err != nil || cmd {
+		t.Errorf("IsCommandDir with cgo enabled = %v, %v, want false, nil", cmd, err)
+	}
+
+	delete(files, "c.go")
+	delete(files, "main.go")
+	if _, err := ctxt.IsCommandDir("/virtual"); err == nil {
+		t.Errorf("IsCommandDir of directory without package files succeeded")
+	} else if _, ok := err.(*NoGoError); !ok {
+		t.Errorf("IsCommandDir of directory without package files: %v, want *NoGoError", err)
+	}
+}
+
+func TestHashSources(t *testing.T) {
+	files := map[string]string{
+		"a.go":      "package p\n",
+		"a_test.go": "package p\n\nfunc f() {}\n",
+		"b.c":       "int x;\n",
+	}
+	ctxt := fakeContext(files)
+	hash := func() string {
+		p, err := ctxt.ImportDir("/virtual", 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var b bytes.Buffer
+		if err := ctxt.HashSources(p, &b); err != nil {
+			t.Fatal(err)
+		}
+		return b.String()
+	}
+
+	h := hash()
+	want := "go \"a.go\" 10\npackage p\nc \"b.c\" 7\nint x;\n"
+	if h != want {
+		t.Errorf("HashSources wrote %q, want %q", h, want)
+	}
+	if h2 := hash(); h2 != h {
+		t.Errorf("HashSources is not stable: %q, then %q", h, h2)
+	}
+	files["a_test.go"] += "func g() {}\n"
+	if h2 := hash(); h2 != h {
+		t.Errorf("HashSources changed when a test file changed")
+	}
+	files["b.c"] = "int y;\n"
+	if h2 := hash(); h2 == h {
+		t.Errorf("HashSources did not change when b.c changed")
+	}
+}
+
+func TestFileConstraints(t *testing.T) {
+	files := make(map[string]string)
+	for _, name := range []string{"README_linux", "a.go", "a_amd64.s", "a_linux.go", "a_linux_test.go", "a_windows_386.go", "linux.go"} {
+		files[name] = "package p\n"
+	}
+	ctxt := fakeContext(files)
+	p, err := ctxt.ImportDir("/virtual", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"a_amd64.s":        "amd64",
+		"a_linux.go":       "linux",
+		"a_linux_test.go":  "linux",
+		"a_windows_386.go": "windows/386",
+		"linux.go":         "linux",
+	}
+	if !reflect.DeepEqual(p.FileConstraints, want) {
+		t.Errorf("FileConstraints = %v, want %v", p.FileConstraints, want)
+	}
+}
+
+func TestDeps(t *testing.T) {
+	deps, err := Deps("./other", "testdata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{filepath.Join("testdata", "other", "file")}; !reflect.DeepEqual(deps, want) {
+		t.Errorf("Deps(./other) = %v, want %v", deps, want)
+	}
+
+	// Compare against a direct walk of the import graph.
+	want := map[string]bool{}
+	var walk func(path string)
+	walk = func(path string) {
+		p, err := Import(path, "", 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, imp := range p.Imports {
+			if imp != "C" && !want[imp] {
+				want[imp] = true
+				walk(imp)
+			}
+		}
+	}
+	walk("net/http")
+
+	deps, err = Deps("net/http", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sort.StringsAreSorted(deps) {
+		t.Errorf("Deps(net/http) = %v, not sorted", deps)
+	}
+	if len(deps) != len(want) {
+		t.Errorf("Deps(net/http) returned %d packages, want %d", len(deps), len(want))
+	}
+	for _, dep := range deps {
+		if !want[dep] {
+			t.Errorf("Deps(net/http) includes %s, which net/http does not import", dep)
+		}
+	}
+
+	if _, err := Deps("./nonexistent", "testdata"); err == nil {
+		t.Errorf("Deps(./nonexistent) succeeded, want error")
+	}
+}
@@ -12,6 +12,9 @@ import (
 	"bytes"
 	"go/ast"
 	"go/token"
+	"sort"
+	"strconv"
+	"strings"
 	"unicode/utf8"
 )
 
@@ -40,7 +43,7 @@ import (
 //            future (not yet interspersed) comments in this function.
 //
 func (p *printer) linebreak(line, min int, ws whiteSpace, newSection bool) (printedBreak bool) {
-	n := nlimit(line - p.pos.Line)
+	n := p.nlimit(line - p.pos.Line)
 	if n < min {
 		n = min
 	}
@@ -428,6 +431,10 @@ func (p *printer) fieldList(fields *ast.FieldList, isStruct, isIncomplete bool)
 		if len(list) == 1 {
 			sep = blank
 		}
+		// In AlignComments mode, fields without a tag leave the tag
+		// column empty if any field has a tag, so that line comments
+		// line up after the tags.
+		tagColumn := p.Config.Mode&AlignComments != 0 && sep == vtab && hasFieldTags(list)
 		newSection := false
 		for i, f := range list {
 			if i > 0 {
@@ -446,8 +453,15 @@ func (p *printer) fieldList(fields *ast.FieldList, isStruct, isIncomplete bool)
 				p.expr(f.Type)
 				extraTabs = 2
 			}
+			if tagColumn && (f.Tag != nil || f.Comment != nil) {
+				if len(f.Names) == 0 {
+					// leave the type column empty
+					p.print(sep)
+				}
+				extraTabs = 3 // skip the tag column
+			}
 			if f.Tag != nil {
-				if len(f.Names) > 0 && sep == vtab {
+				if (len(f.Names) > 0 || tagColumn) && sep == vtab {
 					p.print(sep)
 				}
 				p.print(sep)
@@ -902,19 +916,25 @@ func (p *printer) stmtList(list []ast.Stmt, nindent int, nextIsRBrace bool) {
 		p.print(indent)
 	}
 	multiLine := false
+	var prev ast.Stmt // previous non-empty statement
 	i := 0
 	for _, s := range list {
 		// ignore empty statements (was issue 3466)
 		if _, isEmpty := s.(*ast.EmptyStmt); !isEmpty {
-			// _indent == 0 only for lists of switch/select case clauses;
-			// in those cases each clause is a new section
-			if len(p.output) > 0 {
+			if i > 0 && nindent > 0 && p.Config.Mode&SourceLinebreaks != 0 && p.sameLine(prev, s) {
+				// keep statements that share a line in the source on one line
+				p.commentsBefore(p.posFor(s.Pos()))
+				p.print(token.SEMICOLON, blank)
+			} else if len(p.output) > 0 {
+				// _indent == 0 only for lists of switch/select case clauses;
+				// in those cases each clause is a new section
 				// only print line break if we are not at the beginning of the output
 				// (i.e., we are not printing only a partial program)
 				p.linebreak(p.lineFor(s.Pos()), 1, ignore, i == 0 || nindent == 0 || multiLine)
 			}
 			p.stmt(s, nextIsRBrace && i == len(list)-1)
 			multiLine = p.isMultiLine(s)
+			prev = s
 			i++
 		}
 	}
@@ -923,8 +943,39 @@ func (p *printer) stmtList(list []ast.Stmt, nindent int, nextIsRBrace bool) {
 	}
 }
 
-// block prints an *ast.BlockStmt; it always spans at least two lines.
+// sameLine reports whether statement s follows prev on the same line
+// in the source and prev, which has just been printed, fits on one line.
+// The inexpensive source line checks come first, so that nodeSize is
+// only computed for statements that share a line.
+func (p *printer) sameLine(prev, s ast.Stmt) bool {
+	return p.lineFor(s.Pos()) == p.pos.Line && !p.isMultiLine(prev) &&
+		p.nodeSize(prev, infinity) <= infinity
+}
+
+// commentsBefore writes the pending comments before next, without the
+// separation intersperseComments adds after them. It is used for the
+// comments between statements on one line, which are written before
+// the semicolon.
+func (p *printer) commentsBefore(next token.Position) {
+	var prev *ast.Comment
+	for p.commentBefore(next) {
+		for _, c := range p.comment.List {
+			p.writeCommentPrefix(p.posFor(c.Pos()), next, prev, c, token.SEMICOLON)
+			p.writeComment(c)
+			prev = c
+		}
+		p.nextComment()
+	}
+}
+
+// block prints an *ast.BlockStmt; it always spans at least two lines,
+// unless it is an indented block on one line in the source and the
+// SourceLinebreaks mode is set.
 func (p *printer) block(b *ast.BlockStmt, nindent int) {
+	if nindent > 0 && p.Config.Mode&SourceLinebreaks != 0 && p.bodySize(b, infinity) <= infinity {
+		p.blockOneLine(b)
+		return
+	}
 	p.print(b.Lbrace, token.LBRACE)
 	p.stmtList(b.List, nindent, true)
 	p.linebreak(p.lineFor(b.Rbrace), 1, ignore, true)
@@ -1383,6 +1434,19 @@ func (p *printer) genDecl(d *ast.GenDecl) {
 					p.valueSpec(s.(*ast.ValueSpec), keepType[i])
 					newSection = p.isMultiLine(s)
 				}
+			} else if d.Tok == token.IMPORT && p.Config.Mode&SortImports != 0 && p.canSortImports(d) {
+				// the comments inside d are printed with the specs
+				// they belong to; skip them in the comment stream
+				for end := p.posFor(d.Rparen).Offset; p.commentOffset < end; {
+					p.nextComment()
+				}
+				specs, breaks := p.sortedImports(d.Specs)
+				for i, s := range specs {
+					for j := 0; j < breaks[i]; j++ {
+						p.print(newline)
+					}
+					p.sortedImportSpec(s.(*ast.ImportSpec), n, breaks[i])
+				}
 			} else {
 				newSection := false
 				for i, s := range d.Specs {
@@ -1403,6 +1467,196 @@ func (p *printer) genDecl(d *ast.GenDecl) {
 	}
 }
 
+// hasFieldTags reports whether any of the fields in list has a tag.
+func hasFieldTags(list []*ast.Field) bool {
+	for _, f := range list {
+		if f.Tag != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// canSortImports reports whether the specs of the parenthesized import
+// declaration d may be reordered. The doc and line comments of a spec
+// move with it, but sorting is not attempted if there are any other
+// comments inside the declaration, since they could not be kept next
+// to the imports they belong to.
+func (p *printer) canSortImports(d *ast.GenDecl) bool {
+	attached := make(map[*ast.CommentGroup]bool)
+	for _, s := range d.Specs {
+		s := s.(*ast.ImportSpec)
+		if s.Doc == nil && s.Comment == nil {
+			continue
+		}
+		if p.useNodeComments {
+			// p.spec prints the comments as part of the spec
+			return false
+		}
+		attached[s.Doc] = true
+		attached[s.Comment] = true
+	}
+	end := p.posFor(d.Rparen).Offset
+	if p.commentOffset >= end {
+		return true
+	}
+	if !attached[p.comment] {
+		return false
+	}
+	for _, g := range p.comments[p.cindex:] {
+		if p.posFor(g.Pos()).Offset >= end {
+			break
+		}
+		if !attached[g] {
+			return false
+		}
+	}
+	return true
+}
+
+// sortedImportSpec prints the import spec s of a sorted import declaration
+// with n specs, preceded by breaks line breaks. The doc and line comments
+// of s have been skipped in the comment stream and are written here.
+func (p *printer) sortedImportSpec(s *ast.ImportSpec, n, breaks int) {
+	if s.Doc != nil {
+		// the spec is printed out of source order: pretend that the
+		// last item printed was breaks lines above the doc comment so
+		// that it is separated from it by the same number of lines as
+		// the spec would be without it
+		if breaks < 1 {
+			breaks = 1
+		}
+		p.last = p.posFor(s.Doc.Pos())
+		p.last.Line -= breaks
+		p.specComment(s.Doc)
+		p.writeCommentSuffix(true)
+	}
+	p.spec(s, n, false)
+	if s.Comment != nil {
+		p.specComment(s.Comment)
+	}
+}
+
+// specComment writes the comment group g of a sorted import spec.
+func (p *printer) specComment(g *ast.CommentGroup) {
+	var prev *ast.Comment
+	for _, c := range g.List {
+		p.writeCommentPrefix(p.posFor(c.Pos()), token.Position{}, prev, c, token.ILLEGAL)
+		p.writeComment(c)
+		prev = c
+	}
+}
+
+// sortedImports returns the import specs in the order in which they are
+// printed in SortImports mode, and the number of line breaks to print
+// before each of them. Imports of "C" and imports for side effects only
+// keep their place and split the list into runs; each run is sorted by
+// import path, with standard library imports before all others and
+// separated from them by an empty line. In KeepImportGroups mode, empty
+// lines in the source also end a run, and each run is sorted by import
+// path alone, so that no import moves into another group.
+func (p *printer) sortedImports(specs []ast.Spec) (list []ast.Spec, breaks []int) {
+	// gap returns the number of line breaks between specs[i-1] and specs[i]
+	// in the source.
+	gap := func(i int) int {
+		if i == 0 {
+			return 0
+		}
+		n := p.nlimit(p.lineFor(specs[i].Pos()) - p.lineFor(specs[i-1].End()))
+		if n < 1 {
+			n = 1
+		}
+		return n
+	}
+
+	for i := 0; i < len(specs); {
+		if isFixedImport(specs[i]) {
+			list = append(list, specs[i])
+			breaks = append(breaks, gap(i))
+			i++
+			continue
+		}
+
+		if p.Config.Mode&KeepImportGroups != 0 {
+			j := i + 1
+			for j < len(specs) && !isFixedImport(specs[j]) && gap(j) == 1 {
+				j++
+			}
+			group := append([]ast.Spec(nil), specs[i:j]...)
+			sort.Stable(byImportPath(group))
+			for k, s := range group {
+				list = append(list, s)
+				breaks = append(breaks, gap(i+k))
+			}
+			i = j
+			continue
+		}
+
+		var std, other []ast.Spec
+		j := i
+		for ; j < len(specs) && !isFixedImport(specs[j]); j++ {
+			if isStdImport(specs[j]) {
+				std = append(std, specs[j])
+			} else {
+				other = append(other, specs[j])
+			}
+		}
+		sort.Stable(byImportPath(std))
+		sort.Stable(byImportPath(other))
+
+		n := gap(i)
+		for _, s := range std {
+			list = append(list, s)
+			breaks = append(breaks, n)
+			n = 1
+		}
+		if len(std) > 0 && len(other) > 0 {
+			n = 2
+		}
+		for _, s := range other {
+			list = append(list, s)
+			breaks = append(breaks, n)
+			n = 1
+		}
+		i = j
+	}
+
+	return
+}
+
+// importPath returns the unquoted import path of the import spec s.
+func importPath(s ast.Spec) string {
+	path, err := strconv.Unquote(s.(*ast.ImportSpec).Path.Value)
+	if err != nil {
+		return ""
+	}
+	return path
+}
+
+// isFixedImport reports whether s must not be moved when sorting imports:
+// the import of "C", which must directly follow its cgo preamble, and
+// imports for side effects only.
+func isFixedImport(s ast.Spec) bool {
+	name := s.(*ast.ImportSpec).Name
+	return importPath(s) == "C" || name != nil && name.Name == "_"
+}
+
+// isStdImport reports whether s imports a standard library package,
+// that is a package whose path doesn't start with a domain name.
+func isStdImport(s ast.Spec) bool {
+	path := importPath(s)
+	if i := strings.Index(path, "/"); i >= 0 {
+		path = path[:i]
+	}
+	return !strings.Contains(path, ".")
+}
+
+type byImportPath []ast.Spec
+
+func (x byImportPath) Len() int           { return len(x) }
+func (x byImportPath) Swap(i, j int)      { x[i], x[j] = x[j], x[i] }
+func (x byImportPath) Less(i, j int) bool { return importPath(x[i]) < importPath(x[j]) }
+
 // nodeSize determines the size of n in chars after formatting.
 // The result is <= maxSize if the node fits on one line with at
 // most maxSize chars and the formatted output doesn't contain
@@ -1422,8 +1676,9 @@ func (p *printer) nodeSize(n ast.Node, maxSize int) (size int) {
 
 	// nodeSize computation must be independent of particular
 	// style so that we always get the same decision; print
-	// in RawFormat
-	cfg := Config{Mode: RawFormat}
+	// in RawFormat, keeping source line breaks if they are
+	// kept in the output
+	cfg := Config{Mode: RawFormat | p.Config.Mode&SourceLinebreaks}
 	var buf bytes.Buffer
 	if err := cfg.fprint(&buf, p.fset, n, p.nodeSizes); err != nil {
 		return
@@ -1448,7 +1703,7 @@ func (p *printer) bodySize(b *ast.BlockStmt, maxSize int) int {
 		// opening and closing brace are on different lines - don't make it a one-liner
 		return maxSize + 1
 	}
-	if len(b.List) > 5 || p.commentBefore(p.posFor(pos2)) {
+	if len(b.List) > 5 && p.Config.Mode&SourceLinebreaks == 0 || p.commentBefore(p.posFor(pos2)) {
 		// too many statements or there is a comment inside - don't make it a one-liner
 		return maxSize + 1
 	}
@@ -1475,20 +1730,14 @@ func (p *printer) adjBlock(headerSize int, sep whiteSpace, b *ast.BlockStmt) {
 		return
 	}
 
-	const maxSize = 100
+	maxSize := 100
+	if p.Config.Mode&SourceLinebreaks != 0 {
+		// any block on one line in the source stays on one line
+		maxSize = infinity
+	}
 	if headerSize+p.bodySize(b, maxSize) <= maxSize {
-		p.print(sep, b.Lbrace, token.LBRACE)
-		if len(b.List) > 0 {
-			p.print(blank)
-			for i, s := range b.List {
-				if i > 0 {
-					p.print(token.SEMICOLON, blank)
-				}
-				p.stmt(s, i == len(b.List)-1)
-			}
-			p.print(blank)
-		}
-		p.print(b.Rbrace, token.RBRACE)
+		p.print(sep)
+		p.blockOneLine(b)
 		return
 	}
 
@@ -1498,6 +1747,22 @@ func (p *printer) adjBlock(headerSize int, sep whiteSpace, b *ast.BlockStmt) {
 	p.block(b, 1)
 }
 
+// blockOneLine prints the block b on the current line.
+func (p *printer) blockOneLine(b *ast.BlockStmt) {
+	p.print(b.Lbrace, token.LBRACE)
+	if len(b.List) > 0 {
+		p.print(blank)
+		for i, s := range b.List {
+			if i > 0 {
+				p.print(token.SEMICOLON, blank)
+			}
+			p.stmt(s, i == len(b.List)-1)
+		}
+		p.print(blank)
+	}
+	p.print(b.Rbrace, token.RBRACE)
+}
+
 // distanceFrom returns the column difference between from and p.pos (the current
 // estimated position) if both are on the same line; if they are on different lines
 // (or unknown) the result is infinity.
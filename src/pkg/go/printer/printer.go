@@ -15,10 +15,11 @@ import (
 	"strings"
 	"text/tabwriter"
 	"unicode"
+	"unicode/utf8"
 )
 
 const (
-	maxNewlines = 2     // max. number of newlines between source text
+	maxNewlines = 2     // default max. number of newlines between source text
 	debug       = false // enable for debugging
 	infinity    = 1 << 30
 )
@@ -54,6 +55,7 @@ type printer struct {
 	impliedSemi bool         // if set, a linebreak implies a semicolon
 	lastTok     token.Token  // the last token printed (token.ILLEGAL if it's whitespace)
 	wsbuf       []whiteSpace // delayed white space
+	maxNewlines int          // max. number of newlines between source text
 
 	// Positions
 	// The out position differs from the pos position when the result
@@ -89,6 +91,10 @@ func (p *printer) init(cfg *Config, fset *token.FileSet, nodeSizes map[ast.Node]
 	p.pos = token.Position{Line: 1, Column: 1}
 	p.out = token.Position{Line: 1, Column: 1}
 	p.wsbuf = make([]whiteSpace, 0, 16) // whitespace sequences are short
+	p.maxNewlines = maxNewlines
+	if cfg.MaxEmptyLines > 0 {
+		p.maxNewlines = cfg.MaxEmptyLines + 1
+	}
 	p.nodeSizes = nodeSizes
 	p.cachedPos = -1
 }
@@ -280,7 +286,7 @@ func (p *printer) writeCommentPrefix(pos, next token.Position, prev, comment *as
 
 	if pos.IsValid() && pos.Filename != p.last.Filename {
 		// comment in a different file - separate with newlines
-		p.writeByte('\f', maxNewlines)
+		p.writeByte('\f', p.maxNewlines)
 		return
 	}
 
@@ -390,7 +396,7 @@ func (p *printer) writeCommentPrefix(pos, next token.Position, prev, comment *as
 			// use formfeeds to break columns before a comment;
 			// this is analogous to using formfeeds to separate
 			// individual lines of /*-style comments
-			p.writeByte('\f', nlimit(n))
+			p.writeByte('\f', p.nlimit(n))
 		}
 	}
 }
@@ -579,6 +585,17 @@ func (p *printer) writeComment(comment *ast.Comment) {
 		}
 	}
 
+	const goPrefix = "//go:"
+	if strings.HasPrefix(text, goPrefix) && p.out.Column == 1 {
+		// a directive on a line of its own; it must be written
+		// verbatim and start in the first column to be recognized
+		indent := p.indent
+		p.indent = 0
+		defer func() {
+			p.indent = indent
+		}()
+	}
+
 	// shortcut common case of //-style comments
 	if text[1] == '/' {
 		p.writeString(pos, trimRight(text), true)
@@ -746,10 +763,10 @@ func (p *printer) writeWhitespace(n int) {
 // ----------------------------------------------------------------------------
 // Printing interface
 
-// nlines limits n to maxNewlines.
-func nlimit(n int) int {
-	if n > maxNewlines {
-		n = maxNewlines
+// nlimit limits n to p.maxNewlines.
+func (p *printer) nlimit(n int) int {
+	if n > p.maxNewlines {
+		n = p.maxNewlines
 	}
 	return n
 }
@@ -830,6 +847,9 @@ func (p *printer) print(args ...interface{}) {
 
 		case *ast.BasicLit:
 			data = x.Value
+			if x.Kind == token.STRING && p.Config.Mode&CanonicalStrings != 0 {
+				data = canonicalString(data)
+			}
 			isLit = true
 			impliedSemi = true
 			p.lastTok = x.Kind
@@ -884,10 +904,10 @@ func (p *printer) print(args ...interface{}) {
 		// if they don't cause extra semicolons (don't do this in
 		// flush as it will cause extra newlines at the end of a file)
 		if !p.impliedSemi {
-			n := nlimit(next.Line - p.pos.Line)
+			n := p.nlimit(next.Line - p.pos.Line)
 			// don't exceed maxNewlines if we already wrote one
-			if wroteNewline && n == maxNewlines {
-				n = maxNewlines - 1
+			if wroteNewline && n == p.maxNewlines {
+				n = p.maxNewlines - 1
 			}
 			if n > 0 {
 				ch := byte('\n')
@@ -1137,6 +1157,37 @@ func (p *trimmer) Write(data []byte) (n int, err error) {
 	return
 }
 
+// canonicalString returns the string literal lit in the form used in
+// CanonicalStrings mode: a raw string if the value would need two or
+// more escape sequences as an interpreted string and can be written
+// as a raw string, and an interpreted string otherwise. Literals already
+// in that form, literals whose value contains control characters such
+// as tabs or newlines, and invalid literals are returned unchanged;
+// the value of the literal never changes.
+func canonicalString(lit string) string {
+	s, err := strconv.Unquote(lit)
+	if err != nil {
+		return lit
+	}
+	if strings.IndexFunc(s, unicode.IsControl) >= 0 {
+		// In a raw string they would be invisible or hard to
+		// tell apart from spaces.
+		return lit
+	}
+	raw := lit[0] == '`'
+	quoted := strconv.Quote(s)
+	escapes := strings.Count(quoted, `\`) - strings.Count(s, `\`)
+	wantRaw := escapes >= 2 && strconv.CanBackquote(s) &&
+		utf8.ValidString(s) && !strings.ContainsRune(s, '\uFEFF')
+	switch {
+	case wantRaw && !raw:
+		return "`" + s + "`"
+	case !wantRaw && raw:
+		return quoted
+	}
+	return lit
+}
+
 // ----------------------------------------------------------------------------
 // Public interface
 
@@ -1144,17 +1195,26 @@ func (p *trimmer) Write(data []byte) (n int, err error) {
 type Mode uint
 
 const (
-	RawFormat Mode = 1 << iota // do not use a tabwriter; if set, UseSpaces is ignored
-	TabIndent                  // use tabs for indentation independent of UseSpaces
-	UseSpaces                  // use spaces instead of tabs for alignment
-	SourcePos                  // emit //line comments to preserve original source positions
+	RawFormat        Mode = 1 << iota // do not use a tabwriter; if set, UseSpaces is ignored
+	TabIndent                         // use tabs for indentation independent of UseSpaces
+	UseSpaces                         // use spaces instead of tabs for alignment
+	SourcePos                         // emit //line comments to preserve original source positions
+	SortImports                       // sort parenthesized imports, standard library packages first
+	AlignComments                     // align struct field comments in a column after any field tags
+	SourceLinebreaks                  // keep statements and blocks on one line if they are in the source
+	CanonicalStrings                  // write string literals as raw strings when that avoids several escapes
+	KeepImportGroups                  // with SortImports, sort imports only within groups separated by empty lines
 )
 
 // A Config node controls the output of Fprint.
+//
+// If UseSpaces is set and TabIndent is not, each level of indentation
+// is printed as Tabwidth spaces rather than a tab.
 type Config struct {
-	Mode     Mode // default: 0
-	Tabwidth int  // default: 8
-	Indent   int  // default: 0 (all code is indented at least by this much)
+	Mode          Mode // default: 0
+	Tabwidth      int  // default: 8
+	Indent        int  // default: 0 (all code is indented at least by this much)
+	MaxEmptyLines int  // default: 1 (longer runs of empty lines are collapsed)
 }
 
 // fprint implements Fprint and takes a nodesSizes map for setting up the printer state.
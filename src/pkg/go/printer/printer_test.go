@@ -33,6 +33,11 @@ const (
 	export checkMode = 1 << iota
 	rawFormat
 	idempotent
+	sortImports
+	alignComments
+	sourceLinebreaks
+	canonicalStrings
+	keepImportGroups
 )
 
 // format parses src, prints the corresponding AST, verifies the resulting
@@ -56,6 +61,21 @@ func format(src []byte, mode checkMode) ([]byte, error) {
 	if mode&rawFormat != 0 {
 		cfg.Mode |= RawFormat
 	}
+	if mode&sortImports != 0 {
+		cfg.Mode |= SortImports
+	}
+	if mode&alignComments != 0 {
+		cfg.Mode |= AlignComments
+	}
+	if mode&sourceLinebreaks != 0 {
+		cfg.Mode |= SourceLinebreaks
+	}
+	if mode&canonicalStrings != 0 {
+		cfg.Mode |= CanonicalStrings
+	}
+	if mode&keepImportGroups != 0 {
+		cfg.Mode |= KeepImportGroups
+	}
 
 	// print AST
 	var buf bytes.Buffer
@@ -200,6 +220,11 @@ var data = []entry{
 	{"declarations.input", "declarations.golden", 0},
 	{"statements.input", "statements.golden", 0},
 	{"slow.input", "slow.golden", idempotent},
+	{"sortimports.input", "sortimports.golden", sortImports | idempotent},
+	{"aligncomments.input", "aligncomments.golden", alignComments | idempotent},
+	{"sourcelinebreaks.input", "sourcelinebreaks.golden", sourceLinebreaks | idempotent},
+	{"canonicalstrings.input", "canonicalstrings.golden", canonicalStrings | idempotent},
+	{"importgroups.input", "importgroups.golden", sortImports | keepImportGroups | idempotent},
 }
 
 func TestFiles(t *testing.T) {
@@ -276,6 +301,94 @@ func TestBadNodes(t *testing.T) {
 	}
 }
 
+// Verify that runs of empty lines are collapsed to at most
+// Config.MaxEmptyLines lines, defaulting to one.
+func TestMaxEmptyLines(t *testing.T) {
+	const src = "package p\n\n\n\n\nvar x int\n\n\n\n// f is a function.\nfunc f() {\n\tx++\n\n\n\n\n\tx++\n}\n"
+	for _, test := range []struct {
+		max  int
+		want string
+	}{
+		{0, "package p\n\nvar x int\n\n// f is a function.\nfunc f() {\n\tx++\n\n\tx++\n}\n"},
+		{1, "package p\n\nvar x int\n\n// f is a function.\nfunc f() {\n\tx++\n\n\tx++\n}\n"},
+		{2, "package p\n\n\nvar x int\n\n\n// f is a function.\nfunc f() {\n\tx++\n\n\n\tx++\n}\n"},
+	} {
+		f, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+		if err != nil {
+			t.Fatal(err) // error in test
+		}
+		var buf bytes.Buffer
+		cfg := Config{Tabwidth: tabwidth, MaxEmptyLines: test.max}
+		if err := cfg.Fprint(&buf, fset, f); err != nil {
+			t.Fatal(err)
+		}
+		if got := buf.String(); got != test.want {
+			t.Errorf("MaxEmptyLines = %d: got %q, want %q", test.max, got, test.want)
+		}
+	}
+}
+
+// Verify that UseSpaces without TabIndent indents with Tabwidth spaces
+// and keeps alignment intact.
+func TestUseSpacesIndent(t *testing.T) {
+	const src = "package p\nfunc f() {\nif x {\ny := 1 // c\nzz := 2 // d\n}\n}\n"
+	const want = "package p\n\nfunc f() {\n  if x {\n    y := 1  // c\n    zz := 2 // d\n  }\n}\n"
+	f, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err) // error in test
+	}
+	var buf bytes.Buffer
+	cfg := Config{Mode: UseSpaces, Tabwidth: 2}
+	if err := cfg.Fprint(&buf, fset, f); err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+// Verify that //go: directives on a line of their own are printed
+// unchanged in the first column, even inside indented code.
+func TestGoDirectives(t *testing.T) {
+	const src = `package p
+
+//go:noinline
+func f() {
+	//go:generate echo hello
+	x := 1 //go:trailing
+	if x > 0 {
+		// comment
+			//go:directive
+		x++
+	}
+}
+`
+	const want = `package p
+
+//go:noinline
+func f() {
+//go:generate echo hello
+	x := 1	//go:trailing
+	if x > 0 {
+		// comment
+//go:directive
+		x++
+	}
+}
+`
+	f, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err) // error in test
+	}
+	var buf bytes.Buffer
+	if err := Fprint(&buf, fset, f); err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
 // testComment verifies that f can be parsed again after printing it
 // with its first comment set to comment at any possible source offset.
 func testComment(t *testing.T, f *ast.File, srclen int, comment *ast.Comment) {
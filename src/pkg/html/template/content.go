@@ -25,6 +25,14 @@ type (
 	// and a template escaped by this package are fine for use with HTML.
 	HTML string
 
+	// RawHTML encapsulates an HTML document fragment from a trusted
+	// source that may nonetheless be malformed. It is treated like HTML
+	// if, parsed from HTML text, it ends in HTML text again, as a fragment
+	// with balanced tags, attributes and comments does. Otherwise it could
+	// corrupt the context of the content that follows it, so it is escaped
+	// like a plain string.
+	RawHTML string
+
 	// HTMLAttr encapsulates an HTML attribute from a trusted source,
 	// for example, ` dir="ltr"`.
 	HTMLAttr string
@@ -118,6 +126,11 @@ func stringify(args ...interface{}) (string, contentType) {
 			return string(s), contentTypeCSS
 		case HTML:
 			return string(s), contentTypeHTML
+		case RawHTML:
+			if isBalancedHTML(string(s)) {
+				return string(s), contentTypeHTML
+			}
+			return string(s), contentTypePlain
 		case HTMLAttr:
 			return string(s), contentTypeHTMLAttr
 		case JS:
@@ -221,6 +221,34 @@ func TestTypedContent(t *testing.T) {
 	}
 }
 
+func TestRawHTML(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{`Hello, <b>World</b> &amp;tc!`, `Hello, <b>World</b> &amp;tc!`},
+		{`<a href="/x" title='y'>z</a><!-- c -->`, `<a href="/x" title='y'>z</a><!-- c -->`},
+		{`<script>var x = "</b>";</script>`, `<script>var x = "</b>";</script>`},
+		// Fragments that would leave a non-text context are escaped.
+		{`<b title="`, `&lt;b title=&#34;`},
+		{`<b`, `&lt;b`},
+		{`<!-- open`, `&lt;!-- open`},
+		{`<script>alert(1)`, `&lt;script&gt;alert(1)`},
+		{`<textarea>`, `&lt;textarea&gt;`},
+	}
+	tmpl := Must(New("x").Parse(`{{.}}<a href="{{"/y"}}">`))
+	for _, test := range tests {
+		var b bytes.Buffer
+		if err := tmpl.Execute(&b, RawHTML(test.in)); err != nil {
+			t.Errorf("%q: %s", test.in, err)
+			continue
+		}
+		want := test.want + `<a href="/y">`
+		if got := b.String(); got != want {
+			t.Errorf("%q: want\n\t%q\ngot\n\t%q", test.in, want, got)
+		}
+	}
+}
+
 // Test that we print using the String method. Was issue 3073.
 type stringer struct {
 	v int
@@ -23,9 +23,16 @@ type context struct {
 	attr    attr
 	element element
 	err     *Error
+	// inner is the context in the HTML document that is the value of
+	// a srcdoc attribute, when state is stateSrcdoc.  A nil inner is
+	// the start of that document.
+	inner *context
 }
 
 func (c context) String() string {
+	if c.inner != nil {
+		return fmt.Sprintf("{%v %v %v %v %v %v %v %v}", c.state, c.delim, c.urlPart, c.jsCtx, c.attr, c.element, c.err, *c.inner)
+	}
 	return fmt.Sprintf("{%v %v %v %v %v %v %v}", c.state, c.delim, c.urlPart, c.jsCtx, c.attr, c.element, c.err)
 }
 
@@ -37,7 +44,17 @@ func (c context) eq(d context) bool {
 		c.jsCtx == d.jsCtx &&
 		c.attr == d.attr &&
 		c.element == d.element &&
-		c.err == d.err
+		c.err == d.err &&
+		(c.inner == nil && d.inner == nil || c.innerContext().eq(d.innerContext()))
+}
+
+// innerContext returns the context in the HTML document that is the
+// value of a srcdoc attribute.
+func (c context) innerContext() context {
+	if c.inner == nil {
+		return context{}
+	}
+	return *c.inner
 }
 
 // mangle produces an identifier that includes a suffix that distinguishes it
@@ -63,6 +80,11 @@ func (c context) mangle(templateName string) string {
 	if c.element != 0 {
 		s += "_" + c.element.String()
 	}
+	if c.inner != nil {
+		if m := c.inner.mangle(""); m != "" {
+			s += "_srcdoc" + m
+		}
+	}
 	return s
 }
 
@@ -100,6 +122,10 @@ const (
 	stateRCDATA
 	// stateAttr occurs inside an HTML attribute whose content is text.
 	stateAttr
+	// stateSrcdoc occurs inside an iframe srcdoc attribute, whose content
+	// is an HTML document.  The context in that document is tracked in
+	// context.inner.
+	stateSrcdoc
 	// stateURL occurs inside an HTML attribute whose content is a URL.
 	stateURL
 	// stateJS occurs inside an event handler or script element.
@@ -144,6 +170,7 @@ var stateNames = [...]string{
 	stateHTMLCmt:     "stateHTMLCmt",
 	stateRCDATA:      "stateRCDATA",
 	stateAttr:        "stateAttr",
+	stateSrcdoc:      "stateSrcdoc",
 	stateURL:         "stateURL",
 	stateJS:          "stateJS",
 	stateJSDqStr:     "stateJSDqStr",
@@ -182,7 +209,7 @@ func isComment(s state) bool {
 // isInTag return whether s occurs solely inside an HTML tag.
 func isInTag(s state) bool {
 	switch s {
-	case stateTag, stateAttrName, stateAfterName, stateBeforeValue, stateAttr:
+	case stateTag, stateAttrName, stateAfterName, stateBeforeValue, stateAttr, stateSrcdoc:
 		return true
 	}
 	return false
@@ -322,13 +349,21 @@ const (
 	attrStyle
 	// attrURL corresponds to an attribute whose value is a URL.
 	attrURL
+	// attrSrcdoc corresponds to the srcdoc attribute whose value is HTML.
+	attrSrcdoc
+	// attrEventPrefix corresponds to an attribute whose name so far is
+	// "o", which an action could complete to an event handler name.
+	// Its value is treated like that of attrNone.
+	attrEventPrefix
 )
 
 var attrNames = [...]string{
-	attrNone:   "attrNone",
-	attrScript: "attrScript",
-	attrStyle:  "attrStyle",
-	attrURL:    "attrURL",
+	attrNone:        "attrNone",
+	attrScript:      "attrScript",
+	attrStyle:       "attrStyle",
+	attrURL:         "attrURL",
+	attrSrcdoc:      "attrSrcdoc",
+	attrEventPrefix: "attrEventPrefix",
 }
 
 func (a attr) String() string {
@@ -88,6 +88,7 @@ how {{.}} appears when used in the context to the left.
   Context                          {{.}} After
   {{.}}                            O'Reilly: How are &lt;i&gt;you&lt;/i&gt;?
   <a title='{{.}}'>                O&#39;Reilly: How are you?
+  <iframe srcdoc='{{.}}'>          O&amp;#39;Reilly: How are &amp;lt;i&amp;gt;you&amp;lt;/i&amp;gt;?
   <a href="/{{.}}">                O&#39;Reilly: How are %3ci%3eyou%3c/i%3e?
   <a href="?q={{.}}">              O&#39;Reilly%3a%20How%20are%3ci%3e...%3f
   <a onx='f("{{.}}")'>             O\x27Reilly: How are \x3ci\x3eyou...?
@@ -99,7 +100,8 @@ If used in an unsafe context, then the value might be filtered out:
   Context                          {{.}} After
   <a href="{{.}}">                 #ZgotmplZ
 
-since "O'Reilly:" is not an allowed protocol like "http:".
+since "O'Reilly:" is not an allowed protocol like "http:". The allowed
+protocols can be changed with Template.AllowURLSchemes.
 
 
 If {{.}} is the innocuous word, `left`, then it can appear more widely,
@@ -49,20 +49,22 @@ func escapeTemplates(tmpl *Template, names ...string) error {
 
 // funcMap maps command names to functions that render their inputs safe.
 var funcMap = template.FuncMap{
-	"html_template_attrescaper":     attrEscaper,
-	"html_template_commentescaper":  commentEscaper,
-	"html_template_cssescaper":      cssEscaper,
-	"html_template_cssvaluefilter":  cssValueFilter,
-	"html_template_htmlnamefilter":  htmlNameFilter,
-	"html_template_htmlescaper":     htmlEscaper,
-	"html_template_jsregexpescaper": jsRegexpEscaper,
-	"html_template_jsstrescaper":    jsStrEscaper,
-	"html_template_jsvalescaper":    jsValEscaper,
-	"html_template_nospaceescaper":  htmlNospaceEscaper,
-	"html_template_rcdataescaper":   rcdataEscaper,
-	"html_template_urlescaper":      urlEscaper,
-	"html_template_urlfilter":       urlFilter,
-	"html_template_urlnormalizer":   urlNormalizer,
+	"html_template_attrescaper":      attrEscaper,
+	"html_template_commentescaper":   commentEscaper,
+	"html_template_cssescaper":       cssEscaper,
+	"html_template_cssvaluefilter":   cssValueFilter,
+	"html_template_eventnamefilter":  eventNameFilter,
+	"html_template_eventstartfilter": eventStartFilter,
+	"html_template_htmlnamefilter":   htmlNameFilter,
+	"html_template_htmlescaper":      htmlEscaper,
+	"html_template_jsregexpescaper":  jsRegexpEscaper,
+	"html_template_jsstrescaper":     jsStrEscaper,
+	"html_template_jsvalescaper":     jsValEscaper,
+	"html_template_nospaceescaper":   htmlNospaceEscaper,
+	"html_template_rcdataescaper":    rcdataEscaper,
+	"html_template_urlescaper":       urlEscaper,
+	"html_template_urlfilter":        urlFilter,
+	"html_template_urlnormalizer":    urlNormalizer,
 }
 
 // equivEscapers matches contextual escapers to equivalent template builtins.
@@ -142,11 +144,24 @@ func (e *escaper) escapeAction(c context, n *parse.ActionNode) context {
 		// A local variable assignment, not an interpolation.
 		return c
 	}
+	c, s := e.actionEscapers(c, n)
+	if c.state == stateError {
+		return c
+	}
+	e.editActionNode(n, s)
+	return c
+}
+
+// actionEscapers returns the context after the action n in context c
+// and the names of the escapers its output must pass through.
+func (e *escaper) actionEscapers(c context, n *parse.ActionNode) (context, []string) {
+	// Whether the action starts an attribute name.
+	startsName := c.state == stateTag || c.state == stateAfterName
 	c = nudge(c)
 	s := make([]string, 0, 3)
 	switch c.state {
 	case stateError:
-		return c
+		return c, nil
 	case stateURL, stateCSSDqStr, stateCSSSqStr, stateCSSDqURL, stateCSSSqURL, stateCSSURL:
 		switch c.urlPart {
 		case urlPartNone:
@@ -165,7 +180,7 @@ func (e *escaper) escapeAction(c context, n *parse.ActionNode) context {
 			return context{
 				state: stateError,
 				err:   errorf(ErrAmbigContext, n.Line, "%s appears in an ambiguous URL context", n),
-			}
+			}, nil
 		default:
 			panic(c.urlPart.String())
 		}
@@ -185,9 +200,31 @@ func (e *escaper) escapeAction(c context, n *parse.ActionNode) context {
 		s = append(s, "html_template_rcdataescaper")
 	case stateAttr:
 		// Handled below in delim check.
+	case stateSrcdoc:
+		// The attribute value is itself an HTML document, so the
+		// output is escaped for its context in that document first
+		// and for the attribute below.
+		inner, s1 := e.actionEscapers(c.innerContext(), n)
+		if inner.state == stateError {
+			return inner, nil
+		}
+		c.inner = &inner
+		s = append(s, s1...)
 	case stateAttrName, stateTag:
+		forbid := e.tmpl != nil && e.tmpl.noDynamicHandlers
+		switch {
+		case forbid && c.state == stateAttrName && (c.attr == attrScript || c.attr == attrEventPrefix):
+			// The text so far names an event handler, or is the "o"
+			// that the action could complete to one.
+			s = append(s, "html_template_eventnamefilter")
+		case forbid && startsName:
+			// The action must not emit an "o" that the text after
+			// it completes to an event handler name.
+			s = append(s, "html_template_htmlnamefilter", "html_template_eventstartfilter")
+		default:
+			s = append(s, "html_template_htmlnamefilter")
+		}
 		c.state = stateAttrName
-		s = append(s, "html_template_htmlnamefilter")
 	default:
 		if isComment(c.state) {
 			s = append(s, "html_template_commentescaper")
@@ -203,8 +240,7 @@ func (e *escaper) escapeAction(c context, n *parse.ActionNode) context {
 	default:
 		s = append(s, "html_template_attrescaper")
 	}
-	e.editActionNode(n, s)
-	return c
+	return c, s
 }
 
 // ensurePipelineContains ensures that the pipeline has commands with
@@ -379,6 +415,15 @@ func join(a, b context, line int, nodeName string) context {
 		return c
 	}
 
+	c = a
+	c.attr = b.attr
+	if c.eq(b) && (a.attr == attrNone && b.attr == attrEventPrefix || a.attr == attrEventPrefix && b.attr == attrNone) {
+		// The contexts differ only by whether the attribute name
+		// so far could start an event handler name.  Assume it can.
+		c.attr = attrEventPrefix
+		return c
+	}
+
 	// Allow a nudged context to join with an unnudged one.
 	// This means that
 	//   <p title={{if .C}}{{.}}{{end}}
@@ -645,6 +690,21 @@ func (e *escaper) escapeText(c context, n *parse.TextNode) context {
 	return c
 }
 
+// isBalancedHTML reports whether s, read starting in HTML text,
+// ends in HTML text, so that emitting it unescaped cannot change
+// the context of the content that follows it.
+func isBalancedHTML(s string) bool {
+	c, b := context{}, []byte(s)
+	for len(b) != 0 {
+		c1, n := contextAfterText(c, b)
+		if n == 0 && c1.eq(c) {
+			return false
+		}
+		c, b = c1, b[n:]
+	}
+	return c.eq(context{})
+}
+
 // contextAfterText starts in context c, consumes some tokens from the front of
 // s, then returns the context after those tokens and the unprocessed suffix.
 func contextAfterText(c context, s []byte) (context, int) {
@@ -683,12 +743,30 @@ func contextAfterText(c context, s []byte) (context, int) {
 		// Decode the value so non-HTML rules can easily handle
 		//     <button onclick="alert(&quot;Hi!&quot;)">
 		// without having to entity decode token boundaries.
-		for u := []byte(html.UnescapeString(string(s))); len(u) != 0; {
+		u := []byte(html.UnescapeString(string(s)))
+		if c.state == stateSrcdoc {
+			return srcdocAfterText(c, u), len(s)
+		}
+		for len(u) != 0 {
 			c1, i1 := transitionFunc[c.state](c, u)
 			c, u = c1, u[i1:]
 		}
 		return c, len(s)
 	}
+	if c.state == stateSrcdoc {
+		// The embedded document must end in text, or the escaping
+		// of actions in it could not be relied on.
+		c = srcdocAfterText(c, []byte(html.UnescapeString(string(s[:i]))))
+		if c.state == stateError {
+			return c, len(s)
+		}
+		if inner := c.innerContext(); inner.state != stateText {
+			return context{
+				state: stateError,
+				err:   errorf(ErrEndContext, 0, "srcdoc value ends in a non-text context: %v", inner),
+			}, len(s)
+		}
+	}
 	if c.delim != delimSpaceOrTagEnd {
 		// Consume any quote.
 		i++
@@ -698,6 +776,21 @@ func contextAfterText(c context, s []byte) (context, int) {
 	return context{state: stateTag, element: c.element}, i
 }
 
+// srcdocAfterText returns the context after the decoded text u in the
+// value of a srcdoc attribute, whose content is an HTML document.
+func srcdocAfterText(c context, u []byte) context {
+	inner := c.innerContext()
+	for len(u) != 0 {
+		c1, i := contextAfterText(inner, u)
+		inner, u = c1, u[i:]
+		if inner.state == stateError {
+			return inner
+		}
+	}
+	c.inner = &inner
+	return c
+}
+
 // editActionNode records a change to an action pipeline for later commit.
 func (e *escaper) editActionNode(n *parse.ActionNode, cmds []string) {
 	if _, ok := e.actionNodeEdits[n]; ok {
@@ -727,6 +820,11 @@ func (e *escaper) editTextNode(n *parse.TextNode, text []byte) {
 func (e *escaper) commit() {
 	for name := range e.output {
 		e.template(name).Funcs(funcMap)
+		if e.tmpl != nil && e.tmpl.urlSchemes != nil {
+			e.template(name).Funcs(template.FuncMap{
+				"html_template_urlfilter": urlSchemeFilter(e.tmpl.urlSchemes),
+			})
+		}
 	}
 	for _, t := range e.derived {
 		if _, err := e.tmpl.text.AddParseTree(t.Name(), t.Tree); err != nil {
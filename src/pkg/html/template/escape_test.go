@@ -545,6 +545,56 @@ func TestEscape(t *testing.T) {
 			`<textarea>{{.W}}</textarea>`,
 			`<textarea>&iexcl;&lt;b class=&#34;foo&#34;&gt;Hello&lt;/b&gt;, &lt;textarea&gt;O&#39;World&lt;/textarea&gt;!</textarea>`,
 		},
+		{
+			"printf in text",
+			`{{printf "%s (%d)" .H .N}}`,
+			"&lt;Hello&gt; (42)",
+		},
+		{
+			"printf in attribute",
+			`<a title="{{printf "%s: %q" .C .G}}">`,
+			`<a title="&lt;Cincinatti&gt;: &#34;&lt;Goodbye&gt;&#34;">`,
+		},
+		{
+			"printf in script",
+			`<script>var s = {{printf "%s" .H}};</script>`,
+			`<script>var s = "\u003cHello\u003e";</script>`,
+		},
+		{
+			"text in srcdoc",
+			`<iframe srcdoc="<b>{{.H}}</b>">`,
+			`<iframe srcdoc="<b>&amp;lt;Hello&amp;gt;</b>">`,
+		},
+		{
+			"text in unquoted srcdoc",
+			`<iframe srcdoc={{.H}}>`,
+			`<iframe srcdoc=&amp;lt;Hello&amp;gt;>`,
+		},
+		{
+			"URL in srcdoc",
+			`<iframe srcdoc="<a href='{{"javascript:alert(1)"}}'>x</a>">`,
+			`<iframe srcdoc="<a href='#ZgotmplZ'>x</a>">`,
+		},
+		{
+			"URL query in srcdoc",
+			`<iframe srcdoc="<a href='/search?q={{.H}}&amp;x=y'>x</a>">`,
+			`<iframe srcdoc="<a href='/search?q=%3cHello%3e&amp;x=y'>x</a>">`,
+		},
+		{
+			"attribute in srcdoc",
+			`<iframe srcdoc='<b title="{{.H}}">x</b>'>`,
+			`<iframe srcdoc='<b title="&amp;lt;Hello&amp;gt;">x</b>'>`,
+		},
+		{
+			"script in srcdoc",
+			`<iframe srcdoc="<script>var s = {{.H}};</script>">`,
+			`<iframe srcdoc="<script>var s = &#34;\u003cHello\u003e&#34;;</script>">`,
+		},
+		{
+			"typed HTML in srcdoc",
+			`<iframe srcdoc="{{.W}}">`,
+			`<iframe srcdoc="&amp;iexcl;&lt;b class=&#34;foo&#34;&gt;Hello&lt;/b&gt;, &lt;textarea&gt;O&#39;World&lt;/textarea&gt;!">`,
+		},
 		{
 			"range in textarea",
 			"<textarea>{{range .A}}{{.}}{{end}}</textarea>",
@@ -901,6 +951,14 @@ func TestErrors(t *testing.T) {
 			"<script>foo();",
 			"z: ends in a non-text context: {stateJS",
 		},
+		{
+			`<iframe srcdoc="<a href='{{.H}}">`,
+			"z: srcdoc value ends in a non-text context: {stateURL",
+		},
+		{
+			`<iframe srcdoc="<script>{{.H}}">`,
+			"z: srcdoc value ends in a non-text context: {stateJS",
+		},
 		{
 			`<a href="{{if .F}}/foo?a={{else}}/bar/{{end}}{{.H}}">`,
 			"z:1: {{.H}} appears in an ambiguous URL context",
@@ -1083,6 +1141,14 @@ func TestEscapeText(t *testing.T) {
 			`<a title="`,
 			context{state: stateAttr, delim: delimDoubleQuote},
 		},
+		{
+			`<iframe srcdoc="`,
+			context{state: stateSrcdoc, delim: delimDoubleQuote},
+		},
+		{
+			`<iframe SrcDoc='<b>`,
+			context{state: stateSrcdoc, delim: delimSingleQuote},
+		},
 		{
 			`<a HREF='http:`,
 			context{state: stateURL, delim: delimSingleQuote, urlPart: urlPartPreQuery},
@@ -1618,6 +1684,150 @@ func TestRedundantFuncs(t *testing.T) {
 	}
 }
 
+func TestForbidDynamicEventHandlers(t *testing.T) {
+	tests := []struct {
+		input, want string
+	}{
+		{
+			`<img on{{"load"}}="alert({{"loaded"}})">`,
+			`<img onZgotmplZ="alert(&#34;loaded&#34;)">`,
+		},
+		{
+			`<a on{{"mouse"}}over=x>`,
+			`<a onZgotmplZover=x>`,
+		},
+		{
+			`<input {{"onchange"}}="{{"doEvil()"}}">`,
+			`<input ZgotmplZ="doEvil()">`,
+		},
+		{
+			// A name only partly spelled out by the text.
+			`<img o{{"nclick"}}="{{"alert(1)//'"}}">`,
+			`<img oZgotmplZ="alert(1)//&#39;">`,
+		},
+		{
+			`<img O{{"nClick"}}=x>`,
+			`<img OZgotmplZ=x>`,
+		},
+		{
+			// An action starting the name must not supply the "o".
+			`<img {{"o"}}nclick="{{"alert(1)"}}">`,
+			`<img ZgotmplZnclick="alert(1)">`,
+		},
+		{
+			// Branches that may end in "o" are treated as "o".
+			`<img {{if .}}o{{else}}x{{end}}{{"nclick"}}=y>`,
+			`<img xZgotmplZ=y>`,
+		},
+		{
+			// Other dynamic attribute names are unaffected.
+			`<input {{"checked"}} data-{{"x"}}=y>`,
+			`<input checked data-x=y>`,
+		},
+		{
+			`<input {{"open"}} ol{{"d"}}=y>`,
+			`<input open old=y>`,
+		},
+	}
+	for _, test := range tests {
+		tmpl := Must(New("x").ForbidDynamicEventHandlers().Parse(test.input))
+		// Clones keep the setting.
+		for _, tmpl := range []*Template{Must(tmpl.Clone()), tmpl} {
+			b := new(bytes.Buffer)
+			if err := tmpl.Execute(b, nil); err != nil {
+				t.Errorf("%q: %s", test.input, err)
+				continue
+			}
+			if got := b.String(); got != test.want {
+				t.Errorf("%q: want\n\t%q\ngot\n\t%q", test.input, test.want, got)
+			}
+		}
+	}
+}
+
+func TestAllowURLSchemes(t *testing.T) {
+	tests := []struct {
+		schemes []string // nil means the default set
+		input   string
+		want    string
+	}{
+		{
+			nil,
+			`<a href="{{"tel:+1-555-0100"}}">`,
+			`<a href="tel:&#43;1-555-0100">`,
+		},
+		{
+			nil,
+			`<a href="{{"ftp://example.com/x"}}">`,
+			`<a href="ftp://example.com/x">`,
+		},
+		{
+			nil,
+			`<a href="{{"javascript:alert(1)"}}">`,
+			`<a href="#ZgotmplZ">`,
+		},
+		{
+			[]string{"https"},
+			`<a href="{{"mailto:gopher@example.com"}}">`,
+			`<a href="#ZgotmplZ">`,
+		},
+		{
+			[]string{"https"},
+			`<a href="{{"HTTPS://example.com/"}}">`,
+			`<a href="HTTPS://example.com/">`,
+		},
+		{
+			// Relative URLs are unaffected.
+			[]string{"https"},
+			`<a href="{{"/x:y"}}">`,
+			`<a href="/x:y">`,
+		},
+		{
+			[]string{"HTTPS", "sms"},
+			`<a href="{{"SMS:555"}}"><img src="{{"https://example.com/x.png"}}">`,
+			`<a href="SMS:555"><img src="https://example.com/x.png">`,
+		},
+	}
+	for _, test := range tests {
+		tmpl := New("x")
+		if test.schemes != nil {
+			tmpl = Must(tmpl.AllowURLSchemes(test.schemes...))
+		}
+		tmpl = Must(tmpl.Parse(test.input))
+		// Clones keep the setting.
+		for _, tmpl := range []*Template{Must(tmpl.Clone()), tmpl} {
+			b := new(bytes.Buffer)
+			if err := tmpl.Execute(b, nil); err != nil {
+				t.Errorf("%q: %s", test.input, err)
+				continue
+			}
+			if got := b.String(); got != test.want {
+				t.Errorf("%v %q: want\n\t%q\ngot\n\t%q", test.schemes, test.input, test.want, got)
+			}
+		}
+	}
+}
+
+func TestAllowURLSchemesUnsafe(t *testing.T) {
+	for _, scheme := range []string{"javascript", "VBScript", "data"} {
+		tmpl := Must(New("x").AllowURLSchemes("https"))
+		if _, err := tmpl.AllowURLSchemes("http", scheme); err == nil {
+			t.Errorf("AllowURLSchemes(%q): no error", scheme)
+		}
+		// The allowed set is unchanged.
+		tmpl = Must(tmpl.Parse(`<a href="{{.}}">`))
+		for _, url := range []string{"http://example.com/", scheme + ":alert(1)"} {
+			b := new(bytes.Buffer)
+			if err := tmpl.Execute(b, url); err != nil {
+				t.Fatal(err)
+			}
+			if got, want := b.String(), `<a href="#ZgotmplZ">`; got != want {
+				t.Errorf("after AllowURLSchemes(%q), %q: got %q, want %q", scheme, url, got, want)
+			}
+		}
+	}
+}
+
 func TestIndirectPrint(t *testing.T) {
 	a := 3
 	ap := &a
@@ -1641,6 +1851,32 @@ func TestIndirectPrint(t *testing.T) {
 	}
 }
 
+// Check that actions written with custom delimiters are escaped
+// according to their context, as with the default delimiters,
+// and that the default delimiters are then plain text.
+func TestEscapeDelims(t *testing.T) {
+	data := "<b>O'Reilly</b>"
+	tests := []struct {
+		input, want string
+	}{
+		{`<p>[[.]]</p>`, `<p>&lt;b&gt;O&#39;Reilly&lt;/b&gt;</p>`},
+		{`<a href="/x?q=[[.]]">`, `<a href="/x?q=%3cb%3eO%27Reilly%3c%2fb%3e">`},
+		{`<script>var s = [[.]];</script>`, `<script>var s = "\u003cb\u003eO'Reilly\u003c/b\u003e";</script>`},
+		{`{{.}}[[if .]]yes[[end]]`, `{{.}}yes`},
+	}
+	for _, test := range tests {
+		tmpl := Must(New("delims").Delims("[[", "]]").Parse(test.input))
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, data); err != nil {
+			t.Errorf("%s: execute: %s", test.input, err)
+			continue
+		}
+		if got := buf.String(); got != test.want {
+			t.Errorf("%s: got\n\t%q\nwant\n\t%q", test.input, got, test.want)
+		}
+	}
+}
+
 // This is a test for issue 3272.
 func TestEmptyTemplate(t *testing.T) {
 	page := Must(New("page").ParseFiles(os.DevNull))
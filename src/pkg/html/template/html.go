@@ -214,6 +214,26 @@ func stripTags(html string) string {
 	return b.String()
 }
 
+// eventNameFilter replaces the part of an event handler attribute name
+// produced by an action when ForbidDynamicEventHandlers is in effect.
+// It accepts nothing.
+func eventNameFilter(args ...interface{}) string {
+	return filterFailsafe
+}
+
+// eventStartFilter is applied after htmlNameFilter to an action that
+// starts an attribute name when ForbidDynamicEventHandlers is in
+// effect.  It rejects "o", which the text after the action could
+// complete to an event handler name.  Names starting with "on" are
+// already rejected by htmlNameFilter.
+func eventStartFilter(args ...interface{}) string {
+	s, _ := stringify(args...)
+	if s == "o" {
+		return filterFailsafe
+	}
+	return s
+}
+
 // htmlNameFilter accepts valid parts of an HTML attribute or tag name or
 // a known-safe HTML attribute.
 func htmlNameFilter(args ...interface{}) string {
@@ -9,6 +9,7 @@ import (
 	"io"
 	"io/ioutil"
 	"path/filepath"
+	"strings"
 	"sync"
 	"text/template"
 	"text/template/parse"
@@ -31,6 +32,10 @@ type Template struct {
 type nameSpace struct {
 	mu  sync.Mutex
 	set map[string]*Template
+	// noDynamicHandlers is set by ForbidDynamicEventHandlers.
+	noDynamicHandlers bool
+	// urlSchemes is set by AllowURLSchemes; nil means the default set.
+	urlSchemes map[string]bool
 }
 
 // Templates returns a slice of the templates associated with t, including t
@@ -183,7 +188,9 @@ func (t *Template) Clone() (*Template, error) {
 		textClone,
 		textClone.Tree,
 		&nameSpace{
-			set: make(map[string]*Template),
+			set:               make(map[string]*Template),
+			noDynamicHandlers: t.noDynamicHandlers,
+			urlSchemes:        t.urlSchemes,
 		},
 	}
 	for _, x := range textClone.Templates() {
@@ -261,6 +268,48 @@ func (t *Template) Funcs(funcMap FuncMap) *Template {
 	return t
 }
 
+// ForbidDynamicEventHandlers stops the template and those associated
+// with it from building event handler attributes, such as onclick,
+// whose names are only partly given by the template text: in
+// <img on{{.}}="..."> and <img o{{.}}="...">, the output of the action
+// is replaced by ZgotmplZ, so the attribute is not an event handler,
+// and in <img {{.}}nclick="..."> an action producing "o" is replaced.
+// Attribute names produced entirely by an action are always filtered
+// this way.
+// It must be called before the templates are executed.
+// The return value is the template, so calls can be chained.
+func (t *Template) ForbidDynamicEventHandlers() *Template {
+	t.nameSpace.mu.Lock()
+	t.noDynamicHandlers = true
+	t.nameSpace.mu.Unlock()
+	return t
+}
+
+// AllowURLSchemes sets the URL schemes that the template and those
+// associated with it accept in URLs produced by actions, such as
+// <a href="{{.}}">. A URL with any other scheme is replaced by
+// #ZgotmplZ. Scheme names are not case sensitive. By default the
+// schemes ftp, http, https, mailto, and tel are allowed. Relative URLs
+// and values of type URL are not affected.
+//
+// The javascript, vbscript, and data schemes would let a URL from an
+// untrusted source run script in the page, so AllowURLSchemes refuses
+// them with an error and leaves the allowed set unchanged.
+// To use such a URL from a trusted source, pass it as a URL value.
+//
+// It must be called before the templates are executed.
+func (t *Template) AllowURLSchemes(schemes ...string) (*Template, error) {
+	for _, s := range schemes {
+		if unsafeURLSchemes[strings.ToLower(s)] {
+			return nil, fmt.Errorf("html/template: cannot allow unsafe URL scheme %q", s)
+		}
+	}
+	t.nameSpace.mu.Lock()
+	t.urlSchemes = urlSchemeSet(schemes)
+	t.nameSpace.mu.Unlock()
+	return t, nil
+}
+
 // Delims sets the action delimiters to the specified strings, to be used in
 // subsequent calls to Parse, ParseFiles, or ParseGlob. Nested template
 // definitions will inherit the settings. An empty delimiter stands for the
@@ -22,6 +22,7 @@ var transitionFunc = [...]func(context, []byte) (context, int){
 	stateHTMLCmt:     tHTMLCmt,
 	stateRCDATA:      tSpecialTagEnd,
 	stateAttr:        tAttr,
+	stateSrcdoc:      tAttr,
 	stateURL:         tURL,
 	stateJS:          tJS,
 	stateJSDqStr:     tJSDelimited,
@@ -112,9 +113,15 @@ func tTag(c context, s []byte) (context, int) {
 		attr = attrStyle
 	case contentTypeJS:
 		attr = attrScript
+	case contentTypeHTML:
+		attr = attrSrcdoc
 	}
 	if j == len(s) {
 		state = stateAttrName
+		if attr == attrNone && (s[i] == 'o' || s[i] == 'O') && j == i+1 {
+			// An action may complete the name as on....
+			attr = attrEventPrefix
+		}
 	} else {
 		state = stateAfterName
 	}
@@ -149,10 +156,12 @@ func tAfterName(c context, s []byte) (context, int) {
 }
 
 var attrStartStates = [...]state{
-	attrNone:   stateAttr,
-	attrScript: stateJS,
-	attrStyle:  stateCSS,
-	attrURL:    stateURL,
+	attrNone:        stateAttr,
+	attrScript:      stateJS,
+	attrStyle:       stateCSS,
+	attrURL:         stateURL,
+	attrSrcdoc:      stateSrcdoc,
+	attrEventPrefix: stateAttr,
 }
 
 // tBeforeValue is the context transition function for stateBeforeValue.
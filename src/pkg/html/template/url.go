@@ -10,16 +10,47 @@ import (
 	"strings"
 )
 
+// defaultURLSchemes is the set of URL schemes allowed by urlFilter.
+// Templates may replace it with AllowURLSchemes.
+var defaultURLSchemes = urlSchemeSet([]string{"ftp", "http", "https", "mailto", "tel"})
+
+// unsafeURLSchemes is the set of URL schemes that AllowURLSchemes
+// refuses, since URLs with them can run script.
+var unsafeURLSchemes = urlSchemeSet([]string{"data", "javascript", "vbscript"})
+
+// urlSchemeSet returns the set of the given URL schemes, in lower case.
+func urlSchemeSet(schemes []string) map[string]bool {
+	set := make(map[string]bool, len(schemes))
+	for _, s := range schemes {
+		set[strings.ToLower(s)] = true
+	}
+	return set
+}
+
 // urlFilter returns its input unless it contains an unsafe protocol in which
 // case it defangs the entire URL.
 func urlFilter(args ...interface{}) string {
+	return filterURLScheme(defaultURLSchemes, args...)
+}
+
+// urlSchemeFilter returns a urlFilter that considers safe exactly the
+// URL schemes in allowed.
+func urlSchemeFilter(allowed map[string]bool) func(...interface{}) string {
+	return func(args ...interface{}) string {
+		return filterURLScheme(allowed, args...)
+	}
+}
+
+// filterURLScheme returns its input unless it is a URL whose scheme is not
+// in allowed, in which case it defangs the entire URL.  Relative URLs and
+// values of type URL are always returned unchanged.
+func filterURLScheme(allowed map[string]bool, args ...interface{}) string {
 	s, t := stringify(args...)
 	if t == contentTypeURL {
 		return s
 	}
 	if i := strings.IndexRune(s, ':'); i >= 0 && strings.IndexRune(s[:i], '/') < 0 {
-		protocol := strings.ToLower(s[:i])
-		if protocol != "http" && protocol != "https" && protocol != "mailto" {
+		if !allowed[strings.ToLower(s[:i])] {
 			return "#" + filterFailsafe
 		}
 	}
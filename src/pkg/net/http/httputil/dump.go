@@ -32,6 +32,25 @@ func drainBody(b io.ReadCloser) (r1, r2 io.ReadCloser, err error) {
 	return ioutil.NopCloser(&buf), ioutil.NopCloser(bytes.NewBuffer(buf.Bytes())), nil
 }
 
+// drainBodyPrefix is like drainBody but reads at most n bytes of b.
+// r1 reads the bytes read followed by the rest of b, and closes b;
+// r2 reads just the bytes read. truncated reports whether b held
+// more than n bytes.
+func drainBodyPrefix(b io.ReadCloser, n int64) (r1, r2 io.ReadCloser, truncated bool, err error) {
+	prefix, err := ioutil.ReadAll(io.LimitReader(b, n+1))
+	if err != nil {
+		return nil, nil, false, err
+	}
+	r1 = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(prefix), b), b}
+	if int64(len(prefix)) > n {
+		prefix, truncated = prefix[:n], true
+	}
+	return r1, ioutil.NopCloser(bytes.NewReader(prefix)), truncated, nil
+}
+
 // dumpConn is a net.Conn which writes to Writer and reads from Reader
 type dumpConn struct {
 	io.Writer
@@ -172,14 +191,39 @@ func dumpAsReceived(req *http.Request, w io.Writer) error {
 // The documentation for http.Request.Write details which fields
 // of req are used.
 func DumpRequest(req *http.Request, body bool) (dump []byte, err error) {
+	return dumpRequest(req, body, -1)
+}
+
+// DumpRequestLimit is like DumpRequest with the body included, but it
+// reads at most limit bytes of the body. If the body is longer, the
+// dump ends with the first limit bytes followed by "... (truncated)".
+// The bytes read are kept in memory, and req.Body is changed to read
+// them followed by the rest of the original body, so req can still be
+// used after dumping it.
+func DumpRequestLimit(req *http.Request, limit int64) (dump []byte, err error) {
+	if limit < 0 {
+		limit = 0
+	}
+	return dumpRequest(req, true, limit)
+}
+
+// dumpRequest implements DumpRequest and DumpRequestLimit.
+// A negative limit dumps the whole body.
+func dumpRequest(req *http.Request, body bool, limit int64) (dump []byte, err error) {
 	save := req.Body
+	truncated := false
 	if !body || req.Body == nil {
 		req.Body = nil
-	} else {
+	} else if limit < 0 {
 		save, req.Body, err = drainBody(req.Body)
 		if err != nil {
 			return
 		}
+	} else {
+		save, req.Body, truncated, err = drainBodyPrefix(req.Body, limit)
+		if err != nil {
+			return
+		}
 	}
 
 	var b bytes.Buffer
@@ -216,10 +260,13 @@ func DumpRequest(req *http.Request, body bool) (dump []byte, err error) {
 			dest = NewChunkedWriter(dest)
 		}
 		_, err = io.Copy(dest, req.Body)
-		if chunked {
+		if chunked && !truncated {
 			dest.(io.Closer).Close()
 			io.WriteString(&b, "\r\n")
 		}
+		if truncated {
+			io.WriteString(&b, "... (truncated)")
+		}
 	}
 
 	req.Body = save
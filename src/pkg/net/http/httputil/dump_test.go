@@ -157,6 +157,73 @@ func TestDumpRequest(t *testing.T) {
 	}
 }
 
+// Test that DumpRequestOut leaves the request's Body intact, so the
+// request can still be sent after dumping it.
+func TestDumpRequestOutRestoresBody(t *testing.T) {
+	req := mustNewRequest("POST", "http://post.tld/", bytes.NewBufferString("abcdef"))
+	dump, err := DumpRequestOut(req, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "POST / HTTP/1.1\r\n" +
+		"Host: post.tld\r\n" +
+		"User-Agent: Go 1.1 package http\r\n" +
+		"Content-Length: 6\r\n" +
+		"Accept-Encoding: gzip\r\n\r\n" +
+		"abcdef"
+	if string(dump) != want {
+		t.Errorf("DumpRequestOut, expecting:\n%s\nGot:\n%s\n", want, string(dump))
+	}
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(body) != "abcdef" {
+		t.Errorf("after DumpRequestOut, req.Body = %q; want %q", body, "abcdef")
+	}
+}
+
+var dumpRequestLimitTests = []struct {
+	limit   int64
+	chunked bool
+	want    string
+}{
+	{-1, false, "... (truncated)"},
+	{0, false, "... (truncated)"},
+	{3, false, "abc... (truncated)"},
+	{5, false, "abcde... (truncated)"},
+	{6, false, "abcdef"},
+	{100, false, "abcdef"},
+	{3, true, chunk("abc") + "... (truncated)"},
+	{6, true, chunk("abcdef") + chunk("")},
+}
+
+func TestDumpRequestLimit(t *testing.T) {
+	for _, tt := range dumpRequestLimitTests {
+		req := mustNewRequest("POST", "http://post.tld/", bytes.NewBufferString("abcdef"))
+		head := "POST / HTTP/1.1\r\nHost: post.tld\r\n\r\n"
+		if tt.chunked {
+			req.TransferEncoding = []string{"chunked"}
+			head = "POST / HTTP/1.1\r\nHost: post.tld\r\nTransfer-Encoding: chunked\r\n\r\n"
+		}
+		dump, err := DumpRequestLimit(req, tt.limit)
+		if err != nil {
+			t.Errorf("DumpRequestLimit(%d): %v", tt.limit, err)
+			continue
+		}
+		if want := head + tt.want; string(dump) != want {
+			t.Errorf("DumpRequestLimit(%d), chunked=%v:\nexpecting: %q\ngot:       %q", tt.limit, tt.chunked, want, dump)
+		}
+		body, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(body) != "abcdef" {
+			t.Errorf("after DumpRequestLimit(%d), req.Body = %q; want %q", tt.limit, body, "abcdef")
+		}
+	}
+}
+
 func chunk(s string) string {
 	return fmt.Sprintf("%x\r\n%s\r\n", len(s), s)
 }
@@ -66,6 +66,25 @@ var reqWriteExcludeHeader = map[string]bool{
 	"Trailer":           true,
 }
 
+// Headers meant for a proxy, which Request.Write does not send.
+var reqWriteProxyHeader = map[string]bool{
+	"Proxy-Authorization": true,
+	"Proxy-Connection":    true,
+}
+
+// reqWriteNoProxyExcludeHeader is the union of reqWriteExcludeHeader
+// and reqWriteProxyHeader, the headers excluded by Request.Write.
+var reqWriteNoProxyExcludeHeader = func() map[string]bool {
+	m := make(map[string]bool, len(reqWriteExcludeHeader)+len(reqWriteProxyHeader))
+	for k := range reqWriteExcludeHeader {
+		m[k] = true
+	}
+	for k := range reqWriteProxyHeader {
+		m[k] = true
+	}
+	return m
+}()
+
 // A Request represents an HTTP request received by a server
 // or to be sent by a client.
 type Request struct {
@@ -163,6 +182,9 @@ type Request struct {
 	// concatenated, delimited by commas.
 	// For server requests, Trailer is only populated after Body has been
 	// closed or fully consumed.
+	// For client requests using chunked Transfer-Encoding, the keys of
+	// Trailer are announced in the Trailer header and the key/value pairs
+	// are written after the last chunk of the body.
 	// Trailer support is only partially complete.
 	Trailer Header
 
@@ -189,6 +211,13 @@ type Request struct {
 	// otherwise it leaves the field nil.
 	// This field is ignored by the HTTP client.
 	TLS *tls.ConnectionState
+
+	// AbsoluteURI, if true, makes Write send the Request-Line with
+	// an absolute URI built from URL, including the scheme and host,
+	// as WriteProxy does, for gateways that require that form.
+	// The Host header is written as usual.
+	// This field is ignored by the HTTP server.
+	AbsoluteURI bool
 }
 
 // ProtoAtLeast reports whether the HTTP protocol used
@@ -310,7 +339,22 @@ const defaultUserAgent = "Go 1.1 package http"
 //
 // If Body is present, Content-Length is <= 0 and TransferEncoding
 // hasn't been set to "identity", Write adds "Transfer-Encoding:
-// chunked" to the header. Body is closed after it is sent.
+// chunked" to the header. A Body of unknown length that ends within
+// its first 4KB is read before the header is written and is sent with
+// a Content-Length instead.
+// Body is closed after it is sent.
+//
+// Write sends a default User-Agent header unless Header has a
+// "User-Agent" entry. To send no User-Agent at all, set that entry
+// to an empty string or to an empty slice.
+//
+// An "Expect: 100-continue" header is sent unmodified, but Write does
+// not wait for the server's "100 Continue" response: the body
+// immediately follows the header.
+//
+// Proxy-Authorization and Proxy-Connection headers in Header are
+// meant for a proxy and are not sent by Write; use WriteProxy or
+// WriteProxyAuth to send them.
 func (r *Request) Write(w io.Writer) error {
 	return r.write(w, false, nil)
 }
@@ -325,6 +369,20 @@ func (r *Request) WriteProxy(w io.Writer) error {
 	return r.write(w, true, nil)
 }
 
+// WriteProxyAuth is like WriteProxy but also authenticates the request
+// to the proxy. If user is not nil, WriteProxyAuth writes a
+// Proxy-Authorization header with HTTP Basic credentials from user,
+// in place of any Proxy-Authorization header in r.Header.
+// Other headers, including Proxy-Connection, are written as by WriteProxy.
+func (r *Request) WriteProxyAuth(w io.Writer, user *url.Userinfo) error {
+	var extra Header
+	if user != nil {
+		password, _ := user.Password()
+		extra = Header{"Proxy-Authorization": {"Basic " + basicAuth(user.Username(), password)}}
+	}
+	return r.write(w, true, extra)
+}
+
 // extraHeaders may be nil
 func (req *Request) write(w io.Writer, usingProxy bool, extraHeaders Header) error {
 	host := req.Host
@@ -335,12 +393,24 @@ func (req *Request) write(w io.Writer, usingProxy bool, extraHeaders Header) err
 		host = req.URL.Host
 	}
 
+	// AbsoluteURI only changes the form of the Request-URI; unlike
+	// usingProxy, it does not make Write send the proxy headers.
+	absolute := usingProxy || req.AbsoluteURI
+
 	ruri := req.URL.RequestURI()
-	if usingProxy && req.URL.Scheme != "" && req.URL.Opaque == "" {
-		ruri = req.URL.Scheme + "://" + host + ruri
-	} else if req.Method == "CONNECT" && req.URL.Path == "" {
-		// CONNECT requests normally give just the host and port, not a full URL.
+	if req.Method == "CONNECT" && req.URL.Path == "" {
+		// CONNECT requests normally give just the host and port, not a
+		// full URL, even when talking to a proxy.
 		ruri = host
+		if req.URL.Host != "" {
+			ruri = req.URL.Host
+		}
+	} else if req.Method == "OPTIONS" && isAsteriskForm(req.URL) {
+		// OPTIONS requests about the server as a whole, rather
+		// than a specific resource, use the asterisk-form.
+		ruri = "*"
+	} else if absolute && req.URL.Scheme != "" && req.URL.Opaque == "" {
+		ruri = req.URL.Scheme + "://" + host + ruri
 	}
 	// TODO(bradfitz): escape at least newlines in ruri?
 
@@ -360,11 +430,14 @@ func (req *Request) write(w io.Writer, usingProxy bool, extraHeaders Header) err
 	fmt.Fprintf(w, "Host: %s\r\n", host)
 
 	// Use the defaultUserAgent unless the Header contains one, which
-	// may be blank to not send the header.
+	// may be blank, or an empty slice, to not send the header.
 	userAgent := defaultUserAgent
 	if req.Header != nil {
-		if ua := req.Header["User-Agent"]; len(ua) > 0 {
-			userAgent = ua[0]
+		if ua, ok := req.Header["User-Agent"]; ok {
+			userAgent = ""
+			if len(ua) > 0 {
+				userAgent = ua[0]
+			}
 		}
 	}
 	if userAgent != "" {
@@ -381,8 +454,25 @@ func (req *Request) write(w io.Writer, usingProxy bool, extraHeaders Header) err
 		return err
 	}
 
+	// Headers in extraHeaders replace those of the same name in
+	// req.Header. Proxy headers are only sent to proxies.
+	exclude := reqWriteExcludeHeader
+	if !usingProxy {
+		exclude = reqWriteNoProxyExcludeHeader
+	}
+	if len(extraHeaders) > 0 {
+		m := make(map[string]bool, len(exclude)+len(extraHeaders))
+		for k := range exclude {
+			m[k] = true
+		}
+		for k := range extraHeaders {
+			m[k] = true
+		}
+		exclude = m
+	}
+
 	// TODO: split long values?  (If so, should share code with Conn.Write)
-	err = req.Header.WriteSubset(w, reqWriteExcludeHeader)
+	err = req.Header.WriteSubset(w, exclude)
 	if err != nil {
 		return err
 	}
@@ -408,6 +498,17 @@ func (req *Request) write(w io.Writer, usingProxy bool, extraHeaders Header) err
 	return nil
 }
 
+// isAsteriskForm reports whether u, the URL of an OPTIONS request,
+// should be written as the "*" request-target. That is only the case
+// when the caller set its path or opaque part to "*"; an empty path
+// means "/", as for any other method.
+func isAsteriskForm(u *url.URL) bool {
+	if u.RawQuery != "" {
+		return false
+	}
+	return u.Opaque == "*" || u.Path == "*"
+}
+
 // ParseHTTPVersion parses a HTTP version string.
 // "HTTP/1.0" returns (1, 0, true).
 func ParseHTTPVersion(vers string) (major, minor int, ok bool) {
@@ -68,6 +68,27 @@ func TestResponseWrite(t *testing.T) {
 				"6\r\nabcdef\r\n0\r\n\r\n",
 		},
 
+		// HTTP/1.1, chunked coding; trailer keys are announced,
+		// but no trailer values are written
+		{
+			Response{
+				StatusCode:       200,
+				ProtoMajor:       1,
+				ProtoMinor:       1,
+				Request:          dummyReq("GET"),
+				Header:           Header{},
+				Body:             ioutil.NopCloser(bytes.NewBufferString("abcdef")),
+				ContentLength:    -1,
+				TransferEncoding: []string{"chunked"},
+				Trailer:          Header{"Foo": {"bar"}},
+			},
+
+			"HTTP/1.1 200 OK\r\n" +
+				"Transfer-Encoding: chunked\r\n" +
+				"Trailer: Foo\r\n\r\n" +
+				"6\r\nabcdef\r\n0\r\n\r\n",
+		},
+
 		// Header value with a newline character (Issue 914).
 		// Also tests removal of leading and trailing whitespace.
 		{
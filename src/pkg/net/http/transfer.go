@@ -28,8 +28,14 @@ type transferWriter struct {
 	Close            bool
 	TransferEncoding []string
 	Trailer          Header
+	WriteTrailer     bool // write the Trailer values after the last chunk
 }
 
+// smallBodySize is the size up to which a Request body of unknown
+// length is read before writing the header. A body that ends within
+// it is sent with a Content-Length header rather than chunked.
+const smallBodySize = 4 << 10
+
 func newTransferWriter(r interface{}) (t *transferWriter, err error) {
 	t = &transferWriter{}
 
@@ -40,6 +46,9 @@ func newTransferWriter(r interface{}) (t *transferWriter, err error) {
 		if rr.ContentLength != 0 && rr.Body == nil {
 			return nil, fmt.Errorf("http: Request.ContentLength=%d with nil Body", rr.ContentLength)
 		}
+		if rr.ContentLength > 0 && chunked(rr.TransferEncoding) {
+			return nil, errors.New("http: Request has both chunked Transfer-Encoding and non-zero Content-Length")
+		}
 		t.Method = rr.Method
 		t.Body = rr.Body
 		t.BodyCloser = rr.Body
@@ -47,23 +56,31 @@ func newTransferWriter(r interface{}) (t *transferWriter, err error) {
 		t.Close = rr.Close
 		t.TransferEncoding = rr.TransferEncoding
 		t.Trailer = rr.Trailer
+		t.WriteTrailer = true
 		atLeastHTTP11 = rr.ProtoAtLeast(1, 1)
 		if t.Body != nil && len(t.TransferEncoding) == 0 && atLeastHTTP11 {
 			if t.ContentLength == 0 {
-				// Test to see if it's actually zero or just unset.
-				var buf [1]byte
-				n, _ := io.ReadFull(t.Body, buf[:])
-				if n == 1 {
-					// Oh, guess there is data in this Body Reader after all.
-					// The ContentLength field just wasn't set.
-					// Stich the Body back together again, re-attaching our
-					// consumed byte.
-					t.ContentLength = -1
-					t.Body = io.MultiReader(bytes.NewBuffer(buf[:]), t.Body)
-				} else {
+				// Test to see if it's actually zero or just unset,
+				// reading one byte more than smallBodySize to tell
+				// whether the body ends within it. A read error is
+				// not reported here; the rest of the body is then
+				// sent chunked and the error returned by WriteBody.
+				buf, err := ioutil.ReadAll(io.LimitReader(t.Body, smallBodySize+1))
+				switch {
+				case len(buf) == 0 && err == nil:
 					// Body is actually empty.
 					t.Body = nil
 					t.BodyCloser = nil
+				case len(buf) <= smallBodySize && err == nil:
+					// We have the whole body.
+					t.ContentLength = int64(len(buf))
+					t.Body = bytes.NewReader(buf)
+				default:
+					// Stitch the Body back together again,
+					// re-attaching our consumed prefix as the
+					// first chunk.
+					t.ContentLength = -1
+					t.Body = io.MultiReader(bytes.NewReader(buf), t.Body)
 				}
 			}
 			if t.ContentLength < 0 {
@@ -214,9 +231,15 @@ func (t *transferWriter) WriteBody(w io.Writer) (err error) {
 			t.ContentLength, ncopy)
 	}
 
-	// TODO(petar): Place trailer writer code here.
 	if chunked(t.TransferEncoding) {
-		// Last chunk, empty trailer
+		// Write Trailer, following the last chunk.
+		// Only Requests write the trailer values; a Response
+		// just announces its Trailer keys.
+		if t.WriteTrailer && t.Trailer != nil {
+			if err = t.Trailer.Write(w); err != nil {
+				return err
+			}
+		}
 		_, err = io.WriteString(w, "\r\n")
 	}
 
@@ -480,7 +480,7 @@ func (t *Transport) dialConn(cm *connectMethod) (*persistConn, error) {
 		if pa != "" {
 			connectReq.Header.Set("Proxy-Authorization", pa)
 		}
-		connectReq.Write(conn)
+		connectReq.WriteProxy(conn)
 
 		// Read response.
 		// Okay to use and discard buffered reader here, because
@@ -34,6 +34,17 @@ func TestCgoTraceback(t *testing.T) {
 	}
 }
 
+func TestCgoSignalForward(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skipf("signal forwarding is not implemented on %s", runtime.GOOS)
+	}
+	got := executeTest(t, cgoSignalForwardSource, nil)
+	want := "OK\n"
+	if got != want {
+		t.Fatalf("expected %q, but got %q", want, got)
+	}
+}
+
 const cgoSignalDeadlockSource = `
 package main
 
@@ -117,3 +128,38 @@ func main() {
 	fmt.Printf("OK\n")
 }
 `
+
+const cgoSignalForwardSource = `
+package main
+
+/*
+#include <signal.h>
+#include <unistd.h>
+
+static void handler(int sig, siginfo_t *info, void *ctx) {
+	write(1, "OK\n", 3);
+	_exit(0);
+}
+
+// Installed before the Go runtime starts, as a C program
+// embedding Go would do.
+__attribute__((constructor)) static void install(void) {
+	struct sigaction sa;
+
+	sa.sa_sigaction = handler;
+	sigemptyset(&sa.sa_mask);
+	sa.sa_flags = SA_SIGINFO | SA_ONSTACK;
+	sigaction(SIGSEGV, &sa, 0);
+}
+
+static void fault(void) {
+	*(volatile int*)0 = 0;
+}
+*/
+import "C"
+
+func main() {
+	C.fault()
+	println("fault was not delivered")
+}
+`
@@ -109,6 +109,25 @@ func TestLockedDeadlock2(t *testing.T) {
 	testDeadlock(t, lockedDeadlockSource2)
 }
 
+func TestDeadlockSummary(t *testing.T) {
+	output := executeTest(t, lockedDeadlockSource2, nil)
+	want := "fatal error: all goroutines are asleep - deadlock!\n(2 goroutines: 2 select (no cases))\n"
+	if !strings.HasPrefix(output, want) {
+		t.Fatalf("output does not start with %q:\n%s", want, output)
+	}
+}
+
+func TestPanicDuringPanic(t *testing.T) {
+	output := executeTest(t, panicDuringPanicSource, nil)
+	want := "panic: first\n\tpanic during panic: second\n"
+	if !strings.HasPrefix(output, want) {
+		t.Fatalf("output does not start with %q:\n%s", want, output)
+	}
+	if !strings.Contains(output, "main.main()") {
+		t.Fatalf("output does not contain the goroutine stack:\n%s", output)
+	}
+}
+
 func TestGoexitDeadlock(t *testing.T) {
 	output := executeTest(t, goexitDeadlockSource, nil)
 	if output != "" {
@@ -272,3 +291,14 @@ func main() {
 	}
 }
 `
+
+const panicDuringPanicSource = `
+package main
+
+func main() {
+	defer func() {
+		panic("second")
+	}()
+	panic("first")
+}
+`
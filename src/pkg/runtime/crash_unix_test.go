@@ -0,0 +1,100 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build darwin dragonfly freebsd linux netbsd openbsd
+
+package runtime_test
+
+import (
+	"os"
+	"runtime"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+func TestGotracebackCrash(t *testing.T) {
+	if runtime.GOOS == "darwin" && runtime.GOARCH == "amd64" {
+		t.Skip("GOTRACEBACK=crash does not dump core on darwin/amd64")
+	}
+	// Do not leave core files behind.
+	var old syscall.Rlimit
+	if err := syscall.Getrlimit(syscall.RLIMIT_CORE, &old); err != nil {
+		t.Fatalf("Getrlimit: %v", err)
+	}
+	lim := old
+	lim.Cur = 0
+	if err := syscall.Setrlimit(syscall.RLIMIT_CORE, &lim); err != nil {
+		t.Fatalf("Setrlimit: %v", err)
+	}
+	defer syscall.Setrlimit(syscall.RLIMIT_CORE, &old)
+
+	defer os.Setenv("GOTRACEBACK", os.Getenv("GOTRACEBACK"))
+	os.Setenv("GOTRACEBACK", "crash")
+
+	output := executeTest(t, nilDerefSource, nil)
+	want := []string{
+		"panic: runtime error: invalid memory address or nil pointer dereference",
+		"goroutine 1 [running]:",
+		"signal: segmentation fault",
+	}
+	for _, w := range want {
+		if !strings.Contains(output, w) {
+			t.Fatalf("output does not contain %q:\n%s", w, output)
+		}
+	}
+	if i, j := strings.Index(output, want[1]), strings.Index(output, want[2]); i > j {
+		t.Fatalf("traceback printed after the signal:\n%s", output)
+	}
+}
+
+const nilDerefSource = `
+package main
+
+func main() {
+	var p *string
+	println(*p)
+}
+`
+
+func TestNilDerefSignal(t *testing.T) {
+	output := executeTest(t, nilDerefSource, nil)
+	want := "[signal SIGSEGV: segmentation violation code="
+	if !strings.Contains(output, want) {
+		t.Fatalf("output does not contain %q:\n%s", want, output)
+	}
+	if !strings.Contains(output, " addr=0x0 pc=") {
+		t.Fatalf("output does not report the faulting address:\n%s", output)
+	}
+}
+
+func TestSignalTracebackHeader(t *testing.T) {
+	// SIGQUIT takes the fatal signal path rather than sigpanic.
+	output := executeTest(t, sigquitSource, nil)
+	want := "SIGQUIT: quit\nPC="
+	i := strings.Index(output, want)
+	if i < 0 {
+		t.Fatalf("output does not contain %q:\n%s", want, output)
+	}
+	// The stack that caught the signal is labeled like the others.
+	rest := strings.TrimLeft(output[i+len(want):], "0123456789abcdefx\n")
+	if !strings.HasPrefix(rest, "goroutine ") && !strings.HasPrefix(rest, "runtime stack:\n") {
+		t.Fatalf("traceback of the signaled stack has no header:\n%s", output)
+	}
+}
+
+const sigquitSource = `
+package main
+
+import (
+	"os"
+	"syscall"
+	"time"
+)
+
+func main() {
+	syscall.Kill(os.Getpid(), syscall.SIGQUIT)
+	time.Sleep(10 * time.Second)
+}
+`
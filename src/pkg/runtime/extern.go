@@ -51,8 +51,9 @@ If GOTRACEBACK=1, the default behavior is used.
 If GOTRACEBACK=2, the per-goroutine stack traces include run-time functions.
 If GOTRACEBACK=crash, the per-goroutine stack traces include run-time functions,
 and if possible the program crashes in an operating-specific manner instead of
-exiting. For example, on Unix systems, the program raises SIGABRT to trigger a
-core dump.
+exiting. For example, on Unix systems, the program re-raises the signal that
+caused the failure, such as SIGSEGV for a nil pointer dereference, or raises
+SIGABRT otherwise, to trigger a core dump.
 
 The GOARCH, GOOS, GOPATH, and GOROOT environment variables complete
 the set of Go environment variables. They influence the building of Go programs
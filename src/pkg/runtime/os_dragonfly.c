@@ -166,6 +166,14 @@ runtime·unminit(void)
 	runtime·signalstack(nil, 0);
 }
 
+// Unblock all signals on the current thread.
+// Used when crashing from inside a signal handler.
+void
+runtime·unblocksignals(void)
+{
+	runtime·sigprocmask(&sigset_none, nil);
+}
+
 void
 runtime·sigpanic(void)
 {
@@ -215,6 +215,14 @@ runtime·unminit(void)
 	runtime·signalstack(nil, 0);
 }
 
+// Unblock all signals on the current thread.
+// Used when crashing from inside a signal handler.
+void
+runtime·unblocksignals(void)
+{
+	runtime·rtsigprocmask(SIG_SETMASK, &sigset_none, nil, sizeof(Sigset));
+}
+
 void
 runtime·sigpanic(void)
 {
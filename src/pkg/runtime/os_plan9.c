@@ -99,6 +99,13 @@ runtime·crash(void)
 	*(int32*)0 = 0;
 }
 
+int8*
+runtime·signame(int32 sig)
+{
+	USED(sig);
+	return nil;
+}
+
 void
 runtime·get_random_data(byte **rnd, int32 *rnd_len)
 {
@@ -494,3 +494,10 @@ runtime·crash(void)
 	// It's okay to leave this empty for now: if crash returns
 	// the ordinary exit-after-panic happens.
 }
+
+int8*
+runtime·signame(int32 sig)
+{
+	USED(sig);
+	return nil;
+}
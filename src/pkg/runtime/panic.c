@@ -206,6 +206,8 @@ rundefer(void)
 }
 
 // Print all currently active panics.  Used when crashing.
+// A panic raised by a deferred call while an earlier panic
+// was still unwinding is labeled "panic during panic".
 static void
 printpanics(Panic *p)
 {
@@ -213,7 +215,10 @@ printpanics(Panic *p)
 		printpanics(p->link);
 		runtime·printf("\t");
 	}
-	runtime·printf("panic: ");
+	if(p->link && !p->link->recovered)
+		runtime·printf("panic during panic: ");
+	else
+		runtime·printf("panic: ");
 	runtime·printany(p->arg);
 	if(p->recovered)
 		runtime·printf(" [recovered]");
@@ -391,10 +396,17 @@ runtime·dopanic(int32 unused)
 	static bool didothers;
 	bool crash;
 	int32 t;
-
-	if(g->sig != 0)
-		runtime·printf("[signal %x code=%p addr=%p pc=%p]\n",
-			g->sig, g->sigcode0, g->sigcode1, g->sigpc);
+	int8 *name;
+
+	if(g->sig != 0) {
+		name = runtime·signame(g->sig);
+		if(name != nil)
+			runtime·printf("[signal %s code=%p addr=%p pc=%p]\n",
+				name, g->sigcode0, g->sigcode1, g->sigpc);
+		else
+			runtime·printf("[signal %x code=%p addr=%p pc=%p]\n",
+				g->sig, g->sigcode0, g->sigcode1, g->sigpc);
+	}
 
 	if((t = runtime·gotraceback(&crash)) > 0){
 		if(g != m->g0) {
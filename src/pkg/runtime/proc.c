@@ -99,6 +99,7 @@ static void sysmon(void);
 static uint32 retake(int64);
 static void incidlelocked(int32);
 static void checkdead(void);
+static void printdeadlock(void);
 static void exitsyscall0(G*);
 static void park0(G*);
 static void goexit0(G*);
@@ -2343,7 +2344,57 @@ checkdead(void)
 	if(grunning == 0)  // possible if main goroutine calls runtime·Goexit()
 		runtime·exit(0);
 	m->throwing = -1;  // do not dump full stacks
-	runtime·throw("all goroutines are asleep - deadlock!");
+	runtime·startpanic();
+	runtime·printf("fatal error: all goroutines are asleep - deadlock!\n");
+	printdeadlock();
+	runtime·dopanic(0);
+}
+
+// printdeadlock prints the number of blocked goroutines
+// and how many are waiting for each reason, for example
+// "(4 goroutines: 3 chan receive, 1 select)".
+static void
+printdeadlock(void)
+{
+	enum { Nreason = 8 };
+	struct {
+		int8	*reason;
+		int32	n;
+	} hist[Nreason];
+	G *gp;
+	int8 *reason;
+	int32 i, nhist, nother, total;
+
+	nhist = 0;
+	nother = 0;
+	total = 0;
+	for(gp = runtime·allg; gp; gp = gp->alllink) {
+		if(gp->isbackground || gp->issystem || gp->status != Gwaiting)
+			continue;
+		total++;
+		reason = gp->waitreason;
+		if(reason == nil)
+			reason = "waiting";
+		for(i = 0; i < nhist; i++)
+			if(runtime·strcmp((byte*)hist[i].reason, (byte*)reason) == 0)
+				break;
+		if(i < nhist)
+			hist[i].n++;
+		else if(nhist < Nreason) {
+			hist[nhist].reason = reason;
+			hist[nhist].n = 1;
+			nhist++;
+		} else
+			nother++;
+	}
+	if(total == 0)
+		return;
+	runtime·printf("(%d goroutine%s:", total, total == 1 ? "" : "s");
+	for(i = 0; i < nhist; i++)
+		runtime·printf("%s %d %s", i == 0 ? "" : ",", hist[i].n, hist[i].reason);
+	if(nother > 0)
+		runtime·printf(", %d other", nother);
+	runtime·printf(")\n");
 }
 
 static void
@@ -122,3 +122,39 @@ func TestRuntimeGogoBytes(t *testing.T) {
 
 	t.Fatalf("go tool nm did not report size for runtime.gogo")
 }
+
+func stackBlocked(c chan bool) {
+	<-c
+}
+
+func TestStack(t *testing.T) {
+	c := make(chan bool)
+	defer close(c)
+	go stackBlocked(c)
+	Gosched()
+
+	buf := make([]byte, 1<<16)
+	n := Stack(buf, false)
+	s := string(buf[:n])
+	if !strings.HasPrefix(s, "goroutine ") || !strings.Contains(s, "runtime_test.TestStack") {
+		t.Fatalf("Stack(false) does not show the calling goroutine:\n%s", s)
+	}
+	if strings.Contains(s, "runtime_test.stackBlocked") {
+		t.Fatalf("Stack(false) shows other goroutines:\n%s", s)
+	}
+
+	n = Stack(buf, true)
+	all := string(buf[:n])
+	if !strings.HasPrefix(all, s[:strings.Index(s, "\n")]) {
+		t.Fatalf("Stack(true) does not start with the calling goroutine:\n%s", all)
+	}
+	if !strings.Contains(all, "runtime_test.stackBlocked") {
+		t.Fatalf("Stack(true) does not show other goroutines:\n%s", all)
+	}
+
+	// A short buffer is filled and the trace truncated.
+	short := make([]byte, 10)
+	if n := Stack(short, true); n != len(short) {
+		t.Fatalf("Stack into %d-byte buffer returned %d", len(short), n)
+	}
+}
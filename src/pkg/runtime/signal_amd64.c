@@ -9,6 +9,7 @@
 #include "os_GOOS.h"
 #include "signal_GOOS_GOARCH.h"
 #include "signals_GOOS.h"
+#include "signal_unix.h"
 
 void
 runtime·dumpregs(Siginfo *info, void *ctxt)
@@ -53,6 +54,9 @@ runtime·sighandler(int32 sig, Siginfo *info, void *ctxt, G *gp)
 
 	t = &runtime·sigtab[sig];
 	if(SIG_CODE0(info, ctxt) != SI_USER && (t->flags & SigPanic)) {
+		// A fault in C code goes to the handler C installed, if any.
+		if(runtime·sigforward(sig, info, ctxt, SIG_RIP(info, ctxt)))
+			return;
 		if(gp == nil || gp == m->g0)
 			goto Throw;
 
@@ -122,6 +126,12 @@ Throw:
 	runtime·printf("\n");
 
 	if(runtime·gotraceback(&crash)){
+		// Label the stack that was running when the signal arrived;
+		// tracebackothers prints the rest after it.
+		if(gp != nil && gp != m->g0)
+			runtime·goroutineheader(gp);
+		else
+			runtime·printf("runtime stack:\n");
 		runtime·traceback(SIG_RIP(info, ctxt), SIG_RSP(info, ctxt), 0, gp);
 		runtime·tracebackothers(gp);
 		runtime·printf("\n");
@@ -129,7 +139,7 @@ Throw:
 	}
 	
 	if(crash)
-		runtime·crash();
+		runtime·crashsig(sig);
 
 	runtime·exit(2);
 }
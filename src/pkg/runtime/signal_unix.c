@@ -11,6 +11,10 @@
 
 extern SigTab runtime·sigtab[];
 
+// Handlers that were installed before the runtime's own.
+// Faults in non-Go code are forwarded to them; see runtime·sigforward.
+static GoSighandler *fwdsig[NSIG];
+
 void
 runtime·initsig(void)
 {
@@ -35,6 +39,7 @@ runtime·initsig(void)
 			}
 		}
 
+		fwdsig[i] = runtime·getsig(i);
 		t->flags |= SigHandling;
 		runtime·setsig(i, runtime·sighandler, true);
 	}
@@ -99,8 +104,61 @@ os·sigpipe(void)
 	runtime·raise(SIGPIPE);
 }
 
+// runtime·sigforward passes a fault to the handler that was installed
+// before the runtime's own, if there is one and the fault happened at
+// pc outside Go code, for example in C code called through cgo.
+// It reports whether the signal was forwarded.
+bool
+runtime·sigforward(int32 sig, Siginfo *info, void *context, uintptr pc)
+{
+#ifdef GOOS_linux
+	GoSighandler *fn;
+
+	if(sig < 0 || sig >= NSIG)
+		return false;
+	fn = fwdsig[sig];
+	if(fn == SIG_DFL || fn == SIG_IGN || fn == runtime·sighandler)
+		return false;
+	if(runtime·findfunc(pc) != nil)
+		return false;
+	runtime·sigfwd(fn, sig, info, context);
+	return true;
+#else
+	// Only Linux knows how to call a C handler (runtime·sigfwd).
+	USED(sig);
+	USED(info);
+	USED(context);
+	USED(pc);
+	return false;
+#endif
+}
+
+int8*
+runtime·signame(int32 sig)
+{
+	if(sig < 0 || sig >= NSIG)
+		return nil;
+	return runtime·sigtab[sig].name;
+}
+
 void
 runtime·crash(void)
+{
+	int32 sig;
+
+	// If the goroutine died because of a signal, such as a
+	// nil pointer dereference, die from that same signal.
+	sig = SIGABRT;
+	if(g != nil && g->sig != 0)
+		sig = g->sig;
+	runtime·crashsig(sig);
+}
+
+// runtime·crashsig resets sig to its default action and raises it,
+// so that the operating system kills the process and writes a core file.
+// Signals whose default action does not dump core are replaced by SIGABRT.
+void
+runtime·crashsig(int32 sig)
 {
 #ifdef GOOS_darwin
 	// OS X core dumps are linear dumps of the mapped memory,
@@ -113,6 +171,24 @@ runtime·crash(void)
 		return;
 #endif
 
-	runtime·setsig(SIGABRT, SIG_DFL, false);
-	runtime·raise(SIGABRT);
+	switch(sig) {
+	case SIGQUIT:
+	case SIGILL:
+	case SIGTRAP:
+	case SIGABRT:
+	case SIGBUS:
+	case SIGFPE:
+	case SIGSEGV:
+	case SIGSYS:
+		break;
+	default:
+		sig = SIGABRT;
+		break;
+	}
+
+	runtime·setsig(sig, SIG_DFL, false);
+	// Signal handlers run with all signals blocked;
+	// unblock them so that the raise takes effect immediately.
+	runtime·unblocksignals();
+	runtime·raise(sig);
 }
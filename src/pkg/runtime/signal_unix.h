@@ -11,4 +11,8 @@ GoSighandler* runtime·getsig(int32);
 
 void	runtime·sighandler(int32 sig, Siginfo *info, void *context, G *gp);
 void	runtime·raise(int32);
+void	runtime·crashsig(int32);
+bool	runtime·sigforward(int32 sig, Siginfo *info, void *context, uintptr pc);
+void	runtime·sigfwd(GoSighandler*, int32, Siginfo*, void*);
+void	runtime·unblocksignals(void);
 
@@ -163,6 +163,23 @@ TEXT runtime·rt_sigaction(SB),NOSPLIT,$0
 	CALL	*runtime·_vdso(SB)
 	RET
 
+// Call fn(sig, info, ctx) using the C calling convention,
+// for forwarding a signal to a handler installed by C code.
+TEXT runtime·sigfwd(SB),NOSPLIT,$0
+	MOVL	4(SP), AX
+	MOVL	8(SP), BX
+	MOVL	12(SP), CX
+	MOVL	16(SP), DX
+	MOVL	SP, SI		// callee-saved in the C ABI
+	SUBL	$12, SP
+	ANDL	$~15, SP	// alignment for gcc ABI
+	MOVL	BX, 0(SP)
+	MOVL	CX, 4(SP)
+	MOVL	DX, 8(SP)
+	CALL	AX
+	MOVL	SI, SP
+	RET
+
 TEXT runtime·sigtramp(SB),NOSPLIT,$44
 	get_tls(CX)
 
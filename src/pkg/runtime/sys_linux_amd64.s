@@ -214,6 +214,19 @@ TEXT runtime·sigtramp(SB),NOSPLIT,$64
 	MOVQ	R10, g(BX)
 	RET
 
+// Call fn(sig, info, ctx) using the C calling convention,
+// for forwarding a signal to a handler installed by C code.
+TEXT runtime·sigfwd(SB),NOSPLIT,$0
+	MOVQ	8(SP), AX
+	MOVL	16(SP), DI
+	MOVQ	24(SP), SI
+	MOVQ	32(SP), DX
+	MOVQ	SP, BX		// callee-saved in the C ABI
+	ANDQ	$~15, SP	// alignment for gcc ABI
+	CALL	AX
+	MOVQ	BX, SP
+	RET
+
 TEXT runtime·sigreturn(SB),NOSPLIT,$0
 	MOVL	$15, AX	// rt_sigreturn
 	SYSCALL
@@ -319,6 +319,19 @@ TEXT runtime·sigtramp(SB),NOSPLIT,$24
 
 	RET
 
+// Call fn(sig, info, ctx) using the C calling convention,
+// for forwarding a signal to a handler installed by C code.
+TEXT runtime·sigfwd(SB),NOSPLIT,$0
+	MOVW	0(FP), R11
+	MOVW	4(FP), R0
+	MOVW	8(FP), R1
+	MOVW	12(FP), R2
+	MOVW	R13, R4		// callee-saved in the C ABI
+	BIC	$0x7, R13	// alignment for gcc ABI
+	BL	(R11)
+	MOVW	R4, R13
+	RET
+
 TEXT runtime·rtsigprocmask(SB),NOSPLIT,$0
 	MOVW	0(FP), R0
 	MOVW	4(FP), R1
@@ -50,7 +50,7 @@ data, defined in detail below.
 
 	{{pipeline}}
 		The default textual representation of the value of the pipeline
-		is copied to the output.
+		is copied to the output. A byte slice is copied as text.
 
 	{{if pipeline}} T1 {{end}}
 		If the value of the pipeline is empty, no output is generated;
@@ -76,6 +76,8 @@ data, defined in detail below.
 		slice, or map and T1 is executed. If the value is a map and the
 		keys are of basic type with a defined order ("comparable"), the
 		elements will be visited in sorted key order.
+		If the value is a channel of element type error, receiving a
+		non-nil error stops the iteration and execution returns that error.
 
 	{{range pipeline}} T1 {{else}} T0 {{end}}
 		The value of the pipeline must be an array, slice, map, or channel.
@@ -206,6 +208,17 @@ The initialization has syntax
 where $variable is the name of the variable. An action that declares a
 variable produces no output.
 
+Variables previously declared can also be assigned, using the syntax
+
+	$variable = pipeline
+
+An assignment changes the value of the existing variable, which must be in
+scope, rather than declaring a new one; like a declaration, it produces no
+output. Assigning inside a "range" body to a variable declared outside it
+makes the value visible to later iterations and after the "end":
+
+	{{$last := ""}}{{range .}}{{$last = .}}{{end}}{{$last}}
+
 If a "range" action initializes a variable, the variable is set to the
 successive elements of the iteration.  Also, a "range" may declare two
 variables, separated by a comma:
@@ -281,10 +294,18 @@ Predefined global functions are named as follows.
 	html
 		Returns the escaped HTML equivalent of the textual
 		representation of its arguments.
+	if_then_else
+		Returns its second argument if its first is non-empty and
+		its third argument otherwise, choosing a value inline as
+		"{{if x}}a{{else}}b{{end}}" would choose output. Thus
+		"if_then_else .Done \"done\" \"pending\"" yields one of the
+		two strings. All the arguments are evaluated.
 	index
 		Returns the result of indexing its first argument by the
 		following arguments. Thus "index x 1 2 3" is, in Go syntax,
 		x[1][2][3]. Each indexed item must be a map, slice, or array.
+		A key missing from a map yields the zero value of the map's
+		element type; an index out of range stops execution.
 	js
 		Returns the escaped JavaScript equivalent of the textual
 		representation of its arguments.
@@ -303,6 +324,12 @@ Predefined global functions are named as follows.
 		An alias for fmt.Sprintf
 	println
 		An alias for fmt.Sprintln
+	slice
+		Returns the result of slicing its first argument by the
+		following arguments. Thus "slice x 1 2" is, in Go syntax,
+		x[1:2], and "slice x 1" is x[1:]. The sliced item must be
+		a string, slice, or array, and the indices must lie within
+		its length.
 	urlquery
 		Returns the escaped value of the textual representation of
 		its arguments in a form suitable for embedding in a URL query.
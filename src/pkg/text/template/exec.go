@@ -51,6 +51,18 @@ func (s *state) setVar(n int, value reflect.Value) {
 	s.vars[len(s.vars)-n].value = value
 }
 
+// assignVar overwrites the value of the innermost variable with the given name.
+// Used by assignments.
+func (s *state) assignVar(name string, value reflect.Value) {
+	for i := s.mark() - 1; i >= 0; i-- {
+		if s.vars[i].name == name {
+			s.vars[i].value = value
+			return
+		}
+	}
+	s.errorf("undefined variable: %s", name)
+}
+
 // varValue returns the value of the named variable.
 func (s *state) varValue(name string) reflect.Value {
 	for i := s.mark() - 1; i >= 0; i-- {
@@ -185,22 +197,47 @@ func (s *state) walk(dot reflect.Value, node parse.Node) {
 // are identical in behavior except that 'with' sets dot.
 func (s *state) walkIfOrWith(typ parse.NodeType, dot reflect.Value, pipe *parse.PipeNode, list, elseList *parse.ListNode) {
 	defer s.pop(s.mark())
-	val := s.evalPipeline(dot, pipe)
-	truth, ok := isTrue(val)
-	if !ok {
-		s.errorf("if/with can't use %v", val)
-	}
-	if truth {
-		if typ == parse.NodeWith {
-			s.walk(val, list)
-		} else {
-			s.walk(dot, list)
+	for {
+		val := s.evalPipeline(dot, pipe)
+		truth, ok := isTrue(val)
+		if !ok {
+			s.errorf("if/with can't use %v", val)
+		}
+		if truth {
+			if typ == parse.NodeWith {
+				s.walk(val, list)
+			} else {
+				s.walk(dot, list)
+			}
+			return
 		}
-	} else if elseList != nil {
-		s.walk(dot, elseList)
+		// The parser turns {{if a}}_{{else if b}}_{{end}} into an if whose
+		// else list holds only another if. Walk such chains here rather
+		// than recursively, so long chains don't nest a call per branch.
+		// Variables declared by the earlier conditions stay in scope,
+		// as they would in the nested form.
+		if next := elseIf(typ, elseList); next != nil {
+			s.at(next)
+			pipe, list, elseList = next.Pipe, next.List, next.ElseList
+			continue
+		}
+		if elseList != nil {
+			s.walk(dot, elseList)
+		}
+		return
 	}
 }
 
+// elseIf returns the if node that forms the else branch of an if node,
+// or nil if the else branch is anything else.
+func elseIf(typ parse.NodeType, elseList *parse.ListNode) *parse.IfNode {
+	if typ != parse.NodeIf || elseList == nil || len(elseList.Nodes) != 1 {
+		return nil
+	}
+	next, _ := elseList.Nodes[0].(*parse.IfNode)
+	return next
+}
+
 // isTrue reports whether the value is 'true', in the sense of not the zero of its type,
 // and whether the value has a meaningful truth value.
 func isTrue(val reflect.Value) (truth, ok bool) {
@@ -276,6 +313,11 @@ func (s *state) walkRange(dot reflect.Value, r *parse.RangeNode) {
 			if !ok {
 				break
 			}
+			// A channel of errors reports failure by sending
+			// a non-nil error; stop and return it from Execute.
+			if val.Type().Elem() == errorType && !elem.IsNil() {
+				s.errorf("range: %s", elem.Interface().(error))
+			}
 			oneIteration(reflect.ValueOf(i), elem)
 		}
 		if i == 0 {
@@ -328,7 +370,11 @@ func (s *state) evalPipeline(dot reflect.Value, pipe *parse.PipeNode) (value ref
 		}
 	}
 	for _, variable := range pipe.Decl {
-		s.push(variable.Ident[0], value)
+		if pipe.IsAssign {
+			s.assignVar(variable.Ident[0], value)
+		} else {
+			s.push(variable.Ident[0], value)
+		}
 	}
 	return value
 }
@@ -621,6 +667,8 @@ func (s *state) evalArg(dot reflect.Value, typ reflect.Type, n parse.Node) refle
 		return s.validateType(s.evalPipeline(dot, arg), typ)
 	case *parse.IdentifierNode:
 		return s.evalFunction(dot, arg, arg, nil, zero)
+	case *parse.ChainNode:
+		return s.validateType(s.evalChainNode(dot, arg, nil, zero), typ)
 	}
 	switch typ.Kind() {
 	case reflect.Bool:
@@ -731,6 +779,8 @@ func (s *state) evalEmptyInterface(dot reflect.Value, n parse.Node) reflect.Valu
 		return s.evalVariableNode(dot, n, nil, zero)
 	case *parse.PipeNode:
 		return s.evalPipeline(dot, n)
+	case *parse.ChainNode:
+		return s.evalChainNode(dot, n, nil, zero)
 	}
 	s.errorf("can't handle assignment of %s to empty interface argument", n)
 	panic("not reached")
@@ -759,9 +809,100 @@ func (s *state) printValue(n parse.Node, v reflect.Value) {
 	if !ok {
 		s.errorf("can't print %s of type %s", n, v.Type())
 	}
+	if b, ok := byteSlice(iface); ok {
+		if _, err := s.wr.Write(b); err != nil {
+			s.errorf("%s", err)
+		}
+		return
+	}
+	if s.tmpl.printFields {
+		var b bytes.Buffer
+		printFields(&b, reflect.ValueOf(iface), make(map[uintptr]bool))
+		if _, err := s.wr.Write(b.Bytes()); err != nil {
+			s.errorf("%s", err)
+		}
+		return
+	}
 	fmt.Fprint(s.wr, iface)
 }
 
+// printFields writes v to b as fmt.Fprint would, except that structs
+// are written as {Name: value, ...} listing their exported fields.
+// It is used in PrintFields mode. Pointers to structs are followed,
+// except for a pointer to a struct that is already being printed,
+// which is written as an address so that cyclic values terminate;
+// visiting holds the addresses of those structs.
+func printFields(b *bytes.Buffer, v reflect.Value, visiting map[uintptr]bool) {
+	for v.Kind() == reflect.Interface && !v.IsNil() {
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		b.WriteString("<nil>")
+		return
+	}
+	// Check for methods before following a pointer, since they
+	// may be defined on the pointer type.
+	switch v.Interface().(type) {
+	case error, fmt.Stringer:
+		fmt.Fprint(b, v.Interface())
+		return
+	}
+	if v.Kind() == reflect.Ptr && !v.IsNil() && v.Elem().Kind() == reflect.Struct {
+		p := v.Pointer()
+		if visiting[p] {
+			fmt.Fprintf(b, "%p", v.Interface())
+			return
+		}
+		visiting[p] = true
+		defer delete(visiting, p)
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		b.WriteByte('{')
+		t := v.Type()
+		n := 0
+		for i := 0; i < t.NumField(); i++ {
+			if t.Field(i).PkgPath != "" { // unexported
+				continue
+			}
+			if n > 0 {
+				b.WriteString(", ")
+			}
+			n++
+			b.WriteString(t.Field(i).Name + ": ")
+			printFields(b, v.Field(i), visiting)
+		}
+		b.WriteByte('}')
+	case reflect.Slice, reflect.Array:
+		b.WriteByte('[')
+		for i := 0; i < v.Len(); i++ {
+			if i > 0 {
+				b.WriteByte(' ')
+			}
+			printFields(b, v.Index(i), visiting)
+		}
+		b.WriteByte(']')
+	default:
+		fmt.Fprint(b, v.Interface())
+	}
+}
+
+// byteSlice returns the contents of v if it is a byte slice, which is printed
+// as text rather than as a list of numbers. An Error or String method takes
+// precedence.
+func byteSlice(v interface{}) ([]byte, bool) {
+	switch v.(type) {
+	case error, fmt.Stringer:
+		return nil, false
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice || rv.Type().Elem().Kind() != reflect.Uint8 {
+		return nil, false
+	}
+	return rv.Bytes(), true
+}
+
 // printableValue returns the, possibly indirected, interface value inside v that
 // is best for a call to formatted printer.
 func printableValue(v reflect.Value) (interface{}, bool) {
@@ -773,7 +914,15 @@ func printableValue(v reflect.Value) (interface{}, bool) {
 	}
 
 	if !v.Type().Implements(errorType) && !v.Type().Implements(fmtStringerType) {
-		if v.CanAddr() && (reflect.PtrTo(v.Type()).Implements(errorType) || reflect.PtrTo(v.Type()).Implements(fmtStringerType)) {
+		if reflect.PtrTo(v.Type()).Implements(errorType) || reflect.PtrTo(v.Type()).Implements(fmtStringerType) {
+			// The method has a pointer receiver. If the value is not
+			// addressable, such as a map element or a struct passed by
+			// value, call the method on a copy.
+			if !v.CanAddr() {
+				p := reflect.New(v.Type())
+				p.Elem().Set(v)
+				v = p.Elem()
+			}
 			v = v.Addr()
 		} else {
 			switch v.Kind() {
@@ -91,6 +91,14 @@ type W struct {
 	k int
 }
 
+type bytesType []byte
+
+type bytesStringer []byte
+
+func (b bytesStringer) String() string {
+	return "<" + string(b) + ">"
+}
+
 func (w *W) Error() string {
 	if w == nil {
 		return "nilW"
@@ -273,16 +281,33 @@ var execTests = []execTest{
 	{"$.I", "{{$.I}}", "17", tVal, true},
 	{"$.U.V", "{{$.U.V}}", "v", tVal, true},
 	{"declare in action", "{{$x := $.U.V}}{{$x}}", "v", tVal, true},
+	{"assign in action", "{{$x := 1}}{{$x = $.U.V}}{{$x}}", "v", tVal, true},
+	{"assign in range", "{{$x := 0}}{{range .SI}}{{$x = .}}{{end}}{{$x}}", "5", tVal, true},
+	{"accumulate in range", "{{$s := ``}}{{range .SI}}{{$s = printf `%s<%d>` $s .}}{{end}}{{$s}}", "<3><4><5>", tVal, true},
+	{"assign in if", "{{$x := 1}}{{if $x = 0}}TRUE{{end}}{{$x}}", "0", tVal, true},
+	{"assign to shadowing variable", "{{$x := 1}}{{with $x := 2}}{{$x = 3}}{{$x}}{{end}}{{$x}}", "31", tVal, true},
 
 	// Type with String method.
 	{"V{6666}.String()", "-{{.V0}}-", "-<6666>-", tVal, true},
 	{"&V{7777}.String()", "-{{.V1}}-", "-<7777>-", tVal, true},
 	{"(*V)(nil).String()", "-{{.V2}}-", "-nilV-", tVal, true},
+	{"V{6666}.String() by value", "-{{.}}-", "-<6666>-", V{6666}, true},
+	{"V{6666}.String() in map", "-{{.x}}-", "-<6666>-", map[string]V{"x": {6666}}, true},
+	{"V{6666}.String() in struct by value", "-{{.V0}}-", "-<6666>-", T{V0: V{6666}}, true},
+	{"V{6666}.String() in range", "{{range .}}-{{.}}-{{end}}", "-<6666>-", map[int]V{1: {6666}}, true},
 
 	// Type with Error method.
 	{"W{888}.Error()", "-{{.W0}}-", "-[888]-", tVal, true},
 	{"&W{999}.Error()", "-{{.W1}}-", "-[999]-", tVal, true},
 	{"(*W)(nil).Error()", "-{{.W2}}-", "-nilW-", tVal, true},
+	{"W{888}.Error() by value", "-{{.}}-", "-[888]-", W{888}, true},
+
+	// Byte slices.
+	{"[]byte", "-{{.}}-", "-hello-", []byte("hello"), true},
+	{"named []byte", "-{{.}}-", "-hello-", bytesType("hello"), true},
+	{"*[]byte", "-{{.}}-", "-hello-", &[]byte{'h', 'e', 'l', 'l', 'o'}, true},
+	{"[]byte with String method", "-{{.}}-", "-<hello>-", bytesStringer("hello"), true},
+	{"[]byte in printf", "{{printf `%s` .}}", "hello", []byte("hello"), true},
 
 	// Pointers.
 	{"*int", "{{.PI}}", "23", tVal, true},
@@ -353,6 +378,11 @@ var execTests = []execTest{
 	{"parens: $.GetU in paren", "{{($.GetU).V}}", "v", tVal, true},
 	{"parens: $ in paren in pipe", "{{($ | echo).X}}", "x", tVal, true},
 	{"parens: spaces and args", `{{(makemap "up" "down" "left" "right").left}}`, "right", tVal, true},
+	{"parens: index as interface arg", "{{typeOf (index .SI 0)}}", "int", tVal, true},
+	{"parens: index as interface arg value", "{{echo (index .SI 1)}}", "4", tVal, true},
+	{"parens: field of index as interface arg", "{{echo (index .SMSI 1).eleven}}", "11", tVal, true},
+	{"parens: field of paren as string arg", "{{oneArg ($.GetU).V}}", "oneArg=v", tVal, true},
+	{"parens: field of paren as wrong arg", "{{oneArg (index .SMSI 1).eleven}}", "", tVal, false},
 
 	// If.
 	{"if true", "{{if true}}TRUE{{end}}", "TRUE", tVal, true},
@@ -408,6 +438,9 @@ var execTests = []execTest{
 
 	// Booleans
 	{"not", "{{not true}} {{not false}}", "false true", nil, true},
+	{"if_then_else", `{{if_then_else true "a" "b"}} {{if_then_else 0 1 2}} {{if_then_else .SI .I 0}}`, "a 2 17", tVal, true},
+	{"if_then_else variable", `{{$x := if_then_else .Empty0 "a" "b"}}{{$x}}`, "b", tVal, true},
+	{"if_then_else arity", `{{if_then_else true "a"}}`, "", tVal, false},
 	{"and", "{{and false 0}} {{and 1 0}} {{and 0 true}} {{and 1 1}}", "false 0 0 1", nil, true},
 	{"or", "{{or 0 0}} {{or 1 0}} {{or 0 true}} {{or 1 1}}", "0 1 true 1", nil, true},
 	{"boolean if", "{{if and true 1 `hi`}}TRUE{{else}}FALSE{{end}}", "TRUE", tVal, true},
@@ -424,6 +457,30 @@ var execTests = []execTest{
 	{"map[nil]", "{{index .MSI nil}}", "0", tVal, true},
 	{"map[WRONG]", "{{index .MSI 10}}", "", tVal, false},
 	{"double index", "{{index .SMSI 1 `eleven`}}", "11", tVal, true},
+	{"slice[nil]", "{{index .SI nil}}", "", tVal, false},
+	{"index with variables", "{{$r := 1}}{{$c := 0}}{{index . $r $c}}", "3", [][]int{{1, 2}, {3, 4}}, true},
+	{"index with range indices", "{{range $i, $_ := .}}{{index $ $i $i}}{{end}}", "14", [][]int{{1, 2}, {3, 4}}, true},
+	{"index inner out of range", "{{index . 1 2}}", "", [][]int{{1, 2}, {3, 4}}, false},
+	{"index missing outer key", "{{index . `x` `y`}}", "0", map[string]map[string]int{}, true},
+
+	// Slicing.
+	{"slice[1:]", "{{slice .SI 1}}", "[4 5]", tVal, true},
+	{"slice[1:2]", "{{slice .SI 1 2}}", "[4]", tVal, true},
+	{"slice[3:3]", "{{slice .SI 3 3}}", "[]", tVal, true},
+	{"slice[0:HUGE]", "{{slice .SI 0 10}}", "", tVal, false},
+	{"slice[2:1]", "{{slice .SI 2 1}}", "", tVal, false},
+	{"slice[-1:]", "{{slice .SI -1}}", "", tVal, false},
+	{"slice[WRONG]", "{{slice .SI `hello`}}", "", tVal, false},
+	{"slice no indices", "{{slice .SI}}", "", tVal, false},
+	{"slice too many indices", "{{slice .SI 0 1 2}}", "", tVal, false},
+	{"slice of pointer to slice", "{{slice .PSI 2}}", "[23]", tVal, true},
+	{"slice of string", "{{slice `hello` 1 3}}", "el", tVal, true},
+	{"slice of array", "{{slice . 1}}", "[2 3]", [3]int{1, 2, 3}, true},
+	{"slice of map", "{{slice .MSI 0}}", "", tVal, false},
+	{"slice of nil", "{{slice nil 0}}", "", tVal, false},
+	{"range over slice", "{{range slice .SI 0 2}}<{{.}}>{{end}}", "<3><4>", tVal, true},
+	{"slice piped to len", "{{slice .SI 1 | len}}", "2", tVal, true},
+	{"index piped to slice", "{{1 | slice .SI}}", "[4 5]", tVal, true},
 
 	// Len.
 	{"slice", "{{len .SI}}", "3", tVal, true},
@@ -674,6 +731,103 @@ func TestDelims(t *testing.T) {
 	}
 }
 
+type fieldsPoint struct {
+	X, Y   int
+	hidden string
+}
+
+// fieldsPtrStringer has a String method on its pointer type.
+type fieldsPtrStringer struct {
+	X int
+}
+
+func (*fieldsPtrStringer) String() string {
+	return "P!"
+}
+
+type fieldsLine struct {
+	Name   string
+	Ends   []fieldsPoint
+	Next   *fieldsLine
+	Label  fmt.Stringer
+	Extra  interface{}
+	Marked *int
+}
+
+func TestPrintFields(t *testing.T) {
+	data := fieldsLine{
+		Name:  "a",
+		Ends:  []fieldsPoint{{1, 2, "x"}, {3, 4, "y"}},
+		Next:  &fieldsLine{Name: "b"},
+		Label: bytesStringer("l"),
+	}
+	tests := []struct {
+		input, want string
+	}{
+		{"{{.Ends}}", "[{X: 1, Y: 2} {X: 3, Y: 4}]"},
+		{"{{.Next.Name}} {{.Extra}} {{.Marked}}", "b <no value> <nil>"},
+		{"{{.Next}}", "{Name: b, Ends: [], Next: <nil>, Label: <nil>, Extra: <nil>, Marked: <nil>}"},
+		{"{{.Label}}", "<l>"},
+		{"{{.}}", "{Name: a, Ends: [{X: 1, Y: 2} {X: 3, Y: 4}], Next: {Name: b, Ends: [], Next: <nil>, Label: <nil>, Extra: <nil>, Marked: <nil>}, Label: <l>, Extra: <nil>, Marked: <nil>}"},
+		{`{{template "t" .Ends}}{{define "t"}}{{index . 1}}{{end}}`, "{X: 3, Y: 4}"},
+	}
+	for _, test := range tests {
+		tmpl, err := New("top").PrintFields(true).Parse(test.input)
+		if err != nil {
+			t.Fatalf("%q: parse error: %s", test.input, err)
+		}
+		// Clones keep the setting.
+		clone, err := tmpl.Clone()
+		if err != nil {
+			t.Fatalf("%q: clone error: %s", test.input, err)
+		}
+		for _, tmpl := range []*Template{tmpl, clone} {
+			var b bytes.Buffer
+			if err := tmpl.Execute(&b, data); err != nil {
+				t.Errorf("%q: exec error: %s", test.input, err)
+				continue
+			}
+			if got := b.String(); got != test.want {
+				t.Errorf("%q: got %q; want %q", test.input, got, test.want)
+			}
+		}
+	}
+
+	// String methods on pointer receivers are used.
+	var b bytes.Buffer
+	tmpl := Must(New("top").PrintFields(true).Parse("{{.}} {{.X}}"))
+	if err := tmpl.Execute(&b, &fieldsPtrStringer{1}); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := b.String(), "P! 1"; got != want {
+		t.Errorf("pointer Stringer: got %q; want %q", got, want)
+	}
+
+	// A cyclic value terminates: the pointer back to a struct being
+	// printed is written as an address. The executor dereferences the
+	// top-level pointer, so the cycle is seen one level down.
+	cycle := &fieldsLine{Name: "c"}
+	cycle.Next = cycle
+	b.Reset()
+	if err := Must(New("top").PrintFields(true).Parse("{{.}}")).Execute(&b, cycle); err != nil {
+		t.Fatal(err)
+	}
+	inner := fmt.Sprintf("{Name: c, Ends: [], Next: %p, Label: <nil>, Extra: <nil>, Marked: <nil>}", cycle)
+	want := "{Name: c, Ends: [], Next: " + inner + ", Label: <nil>, Extra: <nil>, Marked: <nil>}"
+	if got := b.String(); got != want {
+		t.Errorf("cyclic value: got %q; want %q", got, want)
+	}
+
+	// The default is unchanged.
+	b.Reset()
+	if err := Must(New("top").Parse("{{.}}")).Execute(&b, fieldsPoint{1, 2, "x"}); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := b.String(), "{1 2 x}"; got != want {
+		t.Errorf("without PrintFields: got %q; want %q", got, want)
+	}
+}
+
 // Check that an error from a method flows back to the top.
 func TestExecuteError(t *testing.T) {
 	b := new(bytes.Buffer)
@@ -693,6 +847,106 @@ func TestExecuteError(t *testing.T) {
 	}
 }
 
+// Check that a non-nil error received while ranging over a
+// channel of errors stops execution and is returned.
+func TestRangeChanError(t *testing.T) {
+	tmpl, err := New("range").Parse("{{range .}}<{{.}}>{{end}}")
+	if err != nil {
+		t.Fatalf("parse error: %s", err)
+	}
+	ch := make(chan error, 3)
+	ch <- nil
+	ch <- myError
+	ch <- nil
+	close(ch)
+	b := new(bytes.Buffer)
+	err = tmpl.Execute(b, ch)
+	if err == nil {
+		t.Fatal("expected error; got none")
+	}
+	if !strings.Contains(err.Error(), myError.Error()) {
+		t.Errorf("expected myError; got %s", err)
+	}
+	if got, want := b.String(), "<<nil>>"; got != want {
+		t.Errorf("output before error: got %q, want %q", got, want)
+	}
+}
+
+// errFunc is an error whose underlying type is a function,
+// a kind that cannot be printed unless it has an Error method.
+type errFunc func() string
+
+func (f errFunc) Error() string { return f() }
+
+// Check that values of type error print using their Error method,
+// whatever the underlying type, and that nil errors print as <nil>.
+func TestPrintError(t *testing.T) {
+	data := map[string]interface{}{
+		"Err":  myError,
+		"Nil":  struct{ E error }{},
+		"Func": struct{ E error }{errFunc(func() string { return "from func" })},
+		"Map":  map[string]error{"a": myError, "b": nil},
+	}
+	for _, test := range []struct {
+		input, want string
+	}{
+		{"{{.Err}}", "my error"},
+		{"{{.Nil.E}}", "<nil>"},
+		{"{{.Func.E}}", "from func"},
+		{"{{.Map.a}} {{.Map.b}}", "my error <nil>"},
+		{"{{index .Map \"a\"}}", "my error"},
+		{"{{print .Nil.E}}", "<nil>"},
+	} {
+		tmpl, err := New("print").Parse(test.input)
+		if err != nil {
+			t.Fatalf("%s: parse error: %s", test.input, err)
+		}
+		b := new(bytes.Buffer)
+		if err := tmpl.Execute(b, data); err != nil {
+			t.Errorf("%s: exec error: %s", test.input, err)
+			continue
+		}
+		if got := b.String(); got != test.want {
+			t.Errorf("%s: got %q, want %q", test.input, got, test.want)
+		}
+	}
+}
+
+// Check that a long else-if chain executes the same as the equivalent
+// nested if statements, including the scope of declared variables.
+func TestElseIfChain(t *testing.T) {
+	const n = 500
+	var chain, nested bytes.Buffer
+	for i := 0; i < n; i++ {
+		if i == 0 {
+			fmt.Fprintf(&chain, "{{if $v%d := eq . %d}}%d", i, i, i)
+		} else {
+			fmt.Fprintf(&chain, "{{else if $v%d := eq . %d}}%d/{{$v%d}}", i, i, i, i-1)
+			fmt.Fprintf(&nested, "{{else}}")
+		}
+		fmt.Fprintf(&nested, "{{if $v%d := eq . %d}}%d", i, i, i)
+		if i > 0 {
+			fmt.Fprintf(&nested, "/{{$v%d}}", i-1)
+		}
+	}
+	chain.WriteString("{{else}}none/{{$v0}}{{end}}")
+	nested.WriteString("{{else}}none/{{$v0}}" + strings.Repeat("{{end}}", n))
+	chainTmpl := Must(New("chain").Parse(chain.String()))
+	nestedTmpl := Must(New("nested").Parse(nested.String()))
+	for _, data := range []int{0, 1, 2, n / 2, n - 1, n, -1} {
+		var got, want bytes.Buffer
+		if err := chainTmpl.Execute(&got, data); err != nil {
+			t.Fatalf("%d: chain: %s", data, err)
+		}
+		if err := nestedTmpl.Execute(&want, data); err != nil {
+			t.Fatalf("%d: nested: %s", data, err)
+		}
+		if got.String() != want.String() {
+			t.Errorf("%d: got %q; want %q", data, got.String(), want.String())
+		}
+	}
+}
+
 const execErrorText = `line 1
 line 2
 line 3
@@ -24,18 +24,20 @@ import (
 type FuncMap map[string]interface{}
 
 var builtins = FuncMap{
-	"and":      and,
-	"call":     call,
-	"html":     HTMLEscaper,
-	"index":    index,
-	"js":       JSEscaper,
-	"len":      length,
-	"not":      not,
-	"or":       or,
-	"print":    fmt.Sprint,
-	"printf":   fmt.Sprintf,
-	"println":  fmt.Sprintln,
-	"urlquery": URLQueryEscaper,
+	"and":          and,
+	"call":         call,
+	"html":         HTMLEscaper,
+	"if_then_else": ifThenElse,
+	"index":        index,
+	"js":           JSEscaper,
+	"len":          length,
+	"not":          not,
+	"or":           or,
+	"print":        fmt.Sprint,
+	"printf":       fmt.Sprintf,
+	"println":      fmt.Sprintln,
+	"slice":        slice,
+	"urlquery":     URLQueryEscaper,
 
 	// Comparisons
 	"eq": eq, // ==
@@ -149,6 +151,51 @@ func index(item interface{}, indices ...interface{}) (interface{}, error) {
 	return v.Interface(), nil
 }
 
+// Slicing.
+
+// slice returns the result of slicing its first argument by the following
+// arguments.  Thus "slice x 1 2" is, in Go syntax, x[1:2], and "slice x 1"
+// is x[1:]. The sliced item must be a string, slice, or array.
+func slice(item interface{}, indices ...interface{}) (interface{}, error) {
+	v, isNil := indirect(reflect.ValueOf(item))
+	if isNil || !v.IsValid() {
+		return nil, fmt.Errorf("slice of nil value")
+	}
+	if len(indices) < 1 || len(indices) > 2 {
+		return nil, fmt.Errorf("slice: want 1 or 2 indices, got %d", len(indices))
+	}
+	switch v.Kind() {
+	case reflect.String, reflect.Slice:
+	case reflect.Array:
+		if !v.CanAddr() {
+			// reflect can only slice addressable arrays.
+			a := reflect.New(v.Type()).Elem()
+			a.Set(v)
+			v = a
+		}
+	default:
+		return nil, fmt.Errorf("can't slice item of type %s", v.Type())
+	}
+	x := [2]int64{0, int64(v.Len())}
+	for i, index := range indices {
+		index := reflect.ValueOf(index)
+		switch index.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			x[i] = index.Int()
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			x[i] = int64(index.Uint())
+		case reflect.Invalid:
+			return nil, fmt.Errorf("cannot slice with nil index")
+		default:
+			return nil, fmt.Errorf("cannot slice with index of type %s", index.Type())
+		}
+	}
+	if x[0] < 0 || x[1] > int64(v.Len()) || x[0] > x[1] {
+		return nil, fmt.Errorf("slice bounds out of range: [%d:%d] with length %d", x[0], x[1], v.Len())
+	}
+	return v.Slice(int(x[0]), int(x[1])).Interface(), nil
+}
+
 // Length
 
 // length returns the length of the item, with an error if it has no defined length.
@@ -251,6 +298,14 @@ func or(arg0 interface{}, args ...interface{}) interface{} {
 	return arg0
 }
 
+// ifThenElse returns a if cond is true and b otherwise.
+func ifThenElse(cond, a, b interface{}) interface{} {
+	if truth(cond) {
+		return a
+	}
+	return b
+}
+
 // not returns the Boolean negation of its argument.
 func not(arg interface{}) (truth bool) {
 	truth, _ = isTrue(reflect.ValueOf(arg))
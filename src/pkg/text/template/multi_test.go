@@ -278,3 +278,40 @@ func TestRedefinition(t *testing.T) {
 		t.Fatalf("expected redefinition error; got %v", err)
 	}
 }
+
+func TestLookup(t *testing.T) {
+	if New("empty").Lookup("empty") != nil {
+		t.Error("Lookup on unparsed template found a template")
+	}
+	root, err := New("root").Parse(`{{define "a"}}A{{end}}root`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := root.New("b")
+	if _, err := b.Parse(`B`); err != nil {
+		t.Fatal(err)
+	}
+	// Every template in the set can find every other.
+	for _, tmpl := range []*Template{root, b, root.Lookup("a")} {
+		for _, name := range []string{"root", "a", "b"} {
+			found := tmpl.Lookup(name)
+			if found == nil {
+				t.Errorf("%s.Lookup(%q) = nil", tmpl.Name(), name)
+				continue
+			}
+			if found.Name() != name {
+				t.Errorf("%s.Lookup(%q).Name() = %q", tmpl.Name(), name, found.Name())
+			}
+		}
+		if found := tmpl.Lookup("missing"); found != nil {
+			t.Errorf("%s.Lookup(%q) = %v, want nil", tmpl.Name(), "missing", found)
+		}
+	}
+	var buf bytes.Buffer
+	if err := root.Lookup("a").Execute(&buf, nil); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "A" {
+		t.Errorf("Lookup(%q).Execute wrote %q, want %q", "a", buf.String(), "A")
+	}
+}
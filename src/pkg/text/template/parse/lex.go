@@ -37,6 +37,7 @@ type itemType int
 
 const (
 	itemError        itemType = iota // error occurred; value is text of error
+	itemAssign                       // equals ('=') introducing an assignment
 	itemBool                         // boolean constant
 	itemChar                         // printable ASCII character; grab bag for comma etc.
 	itemCharConstant                 // character constant
@@ -285,6 +286,8 @@ func lexInsideAction(l *lexer) stateFn {
 			return l.errorf("expected :=")
 		}
 		l.emit(itemColonEquals)
+	case r == '=':
+		l.emit(itemAssign)
 	case r == '|':
 		l.emit(itemPipe)
 	case r == '"':
@@ -12,6 +12,7 @@ import (
 // Make the types prettyprint.
 var itemName = map[itemType]string{
 	itemError:        "error",
+	itemAssign:       "=",
 	itemBool:         "bool",
 	itemChar:         "char",
 	itemCharConstant: "charconst",
@@ -213,6 +214,16 @@ var lexTests = []lexTest{
 		tRight,
 		tEOF,
 	}},
+	{"assignment", "{{$c = 3}}", []item{
+		tLeft,
+		{itemVariable, 0, "$c"},
+		tSpace,
+		{itemAssign, 0, "="},
+		tSpace,
+		{itemNumber, 0, "3"},
+		tRight,
+		tEOF,
+	}},
 	{"variable invocation", "{{$x 23}}", []item{
 		tLeft,
 		{itemVariable, 0, "$x"},
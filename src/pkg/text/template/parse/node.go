@@ -134,13 +134,14 @@ func (t *TextNode) Copy() Node {
 	return &TextNode{NodeType: NodeText, Text: append([]byte{}, t.Text...)}
 }
 
-// PipeNode holds a pipeline with optional declaration
+// PipeNode holds a pipeline with optional declaration or assignment.
 type PipeNode struct {
 	NodeType
 	Pos
-	Line int             // The line number in the input (deprecated; kept for compatibility)
-	Decl []*VariableNode // Variable declarations in lexical order.
-	Cmds []*CommandNode  // The commands in lexical order.
+	Line     int             // The line number in the input (deprecated; kept for compatibility)
+	IsAssign bool            // The variables in Decl are being assigned, not declared.
+	Decl     []*VariableNode // Variable declarations in lexical order.
+	Cmds     []*CommandNode  // The commands in lexical order.
 }
 
 func newPipeline(pos Pos, line int, decl []*VariableNode) *PipeNode {
@@ -160,7 +161,11 @@ func (p *PipeNode) String() string {
 			}
 			s += v.String()
 		}
-		s += " := "
+		if p.IsAssign {
+			s += " = "
+		} else {
+			s += " := "
+		}
 	}
 	for i, c := range p.Cmds {
 		if i > 0 {
@@ -180,6 +185,7 @@ func (p *PipeNode) CopyPipe() *PipeNode {
 		decl = append(decl, d.Copy().(*VariableNode))
 	}
 	n := newPipeline(p.Pos, p.Line, decl)
+	n.IsAssign = p.IsAssign
 	for _, c := range p.Cmds {
 		n.append(c.Copy().(*CommandNode))
 	}
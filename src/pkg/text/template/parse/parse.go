@@ -370,8 +370,10 @@ func (t *Tree) action() (n Node) {
 
 // Pipeline:
 //	declarations? command ('|' command)*
+//	assignment? command ('|' command)*
 func (t *Tree) pipeline(context string) (pipe *PipeNode) {
 	var decl []*VariableNode
+	isAssign := false
 	pos := t.peekNonSpace().pos
 	// Are there declarations?
 	for {
@@ -382,7 +384,16 @@ func (t *Tree) pipeline(context string) (pipe *PipeNode) {
 			// argument variable rather than a declaration. So remember the token
 			// adjacent to the variable so we can push it back if necessary.
 			tokenAfterVariable := t.peek()
-			if next := t.peekNonSpace(); next.typ == itemColonEquals || (next.typ == itemChar && next.val == ",") {
+			if next := t.peekNonSpace(); next.typ == itemAssign && len(decl) == 0 {
+				// An assignment "$x = pipeline" to a variable already in scope.
+				t.nextNonSpace()
+				if context == "range" {
+					t.errorf("cannot assign to variable in range")
+				}
+				t.useVar(v.pos, v.val)
+				decl = append(decl, newVariable(v.pos, v.val))
+				isAssign = true
+			} else if next.typ == itemColonEquals || (next.typ == itemChar && next.val == ",") {
 				t.nextNonSpace()
 				variable := newVariable(v.pos, v.val)
 				decl = append(decl, variable)
@@ -402,6 +413,7 @@ func (t *Tree) pipeline(context string) (pipe *PipeNode) {
 		break
 	}
 	pipe = newPipeline(pos, t.lex.lineNumber(), decl)
+	pipe.IsAssign = isAssign
 	for {
 		switch token := t.nextNonSpace(); token.typ {
 		case itemRightDelim, itemRightParen:
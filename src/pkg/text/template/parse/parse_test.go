@@ -180,6 +180,10 @@ var parseTests = []parseTest{
 		"{{with $x := 3}}{{$x 23}}{{end}}"},
 	{"variable with fields", "{{$.I}}", noError,
 		"{{$.I}}"},
+	{"assignment", "{{$x := 2}}{{$x = 3}}", noError,
+		"{{$x := 2}}{{$x = 3}}"},
+	{"assignment in if", "{{$x := 2}}{{if $x = 3}}{{end}}", noError,
+		"{{$x := 2}}{{if $x = 3}}{{end}}"},
 	{"multi-word command", "{{printf `%d` 23}}", noError,
 		"{{printf `%d` 23}}"},
 	{"pipeline", "{{.X|.Y}}", noError,
@@ -234,6 +238,10 @@ var parseTests = []parseTest{
 	{"variable undefined after end", "{{with $x := 4}}{{end}}{{$x}}", hasError, ""},
 	{"variable undefined in template", "{{template $v}}", hasError, ""},
 	{"declare with field", "{{with $x.Y := 4}}{{end}}", hasError, ""},
+	{"assign to undefined variable", "{{$x = 4}}", hasError, ""},
+	{"assign after end", "{{with $x := 4}}{{end}}{{$x = 5}}", hasError, ""},
+	{"assign in range", "{{$x := 1}}{{range $x = .}}{{end}}", hasError, ""},
+	{"assign two variables", "{{$x := 1}}{{$y := 1}}{{$x, $y = 2}}", hasError, ""},
 	{"template with field ref", "{{template .X}}", hasError, ""},
 	{"template with var", "{{template $v}}", hasError, ""},
 	{"invalid punctuation", "{{printf 3, 4}}", hasError, ""},
@@ -18,6 +18,8 @@ type common struct {
 	// expose reflection to the client.
 	parseFuncs FuncMap
 	execFuncs  map[string]reflect.Value
+	// printFields is set by PrintFields.
+	printFields bool
 }
 
 // Template is the representation of a parsed template. The *parse.Tree
@@ -89,6 +91,7 @@ func (t *Template) Clone() (*Template, error) {
 	for k, v := range t.execFuncs {
 		nt.execFuncs[k] = v
 	}
+	nt.printFields = t.printFields
 	return nt, nil
 }
 
@@ -139,6 +142,22 @@ func (t *Template) Delims(left, right string) *Template {
 	return t
 }
 
+// PrintFields sets whether actions print struct values with the names
+// of their fields. By default a struct is printed as by fmt.Print, such
+// as {Gopher 3}; with PrintFields(true) it is printed as
+// {Name: Gopher, Age: 3}, showing only the exported fields, which makes
+// the output easier to read when debugging a template. Structs inside
+// slices, arrays, and other structs, or pointed to by them, are printed
+// the same way, while values with an Error or String method are still
+// printed by that method. A pointer to a struct that is already being
+// printed, as in a cyclic list, is printed as an address. The setting applies to t and all templates associated with it.
+// The return value is the template, so calls can be chained.
+func (t *Template) PrintFields(on bool) *Template {
+	t.init()
+	t.printFields = on
+	return t
+}
+
 // Funcs adds the elements of the argument map to the template's function map.
 // It panics if a value in the map is not a function with appropriate return
 // type. However, it is legal to overwrite elements of the map. The return